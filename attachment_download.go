@@ -0,0 +1,392 @@
+package bookstack
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// AttachmentDownload is the result of DownloadAttachment. It is either an
+// AttachmentFile or an AttachmentLink.
+type AttachmentDownload interface {
+	attachmentDownload()
+}
+
+// AttachmentFile is an uploaded attachment that has been written out.
+type AttachmentFile struct {
+	ID        int
+	Name      string
+	Extension string
+	// Filename is the name with its extension, suitable for saving the file.
+	Filename string
+	// Size is the number of bytes written.
+	Size int64
+}
+
+// AttachmentLink is an external attachment, which links to a URL instead of
+// holding a file.
+type AttachmentLink struct {
+	ID   int
+	Name string
+	URL  string
+}
+
+func (AttachmentFile) attachmentDownload() {}
+
+func (AttachmentLink) attachmentDownload() {}
+
+// DownloadAttachment will write the file of the attachment that matches id
+// to w. The base64 content of the response is decoded as it is read, so the
+// file is never held in memory. External attachments are returned as an
+// AttachmentLink, and nothing is written to w.
+func (b *Bookstack) DownloadAttachment(ctx context.Context, id int, w io.Writer) (AttachmentDownload, error) {
+
+	resp, err := b.do(ctx, http.MethodGet, fmt.Sprintf("/attachments/%d", id), blank{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return decodeAttachment(bufio.NewReader(resp.Body), w)
+}
+
+type attachmentMeta struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Extension string `json:"extension"`
+	External  bool   `json:"external"`
+}
+
+// decodeAttachment reads an attachment object from r, copying the decoded
+// content to w. The fields before content are read first, so whether the
+// attachment is external is known by the time content is reached, as it is
+// in the responses of BookStack.
+func decodeAttachment(r *bufio.Reader, w io.Writer) (AttachmentDownload, error) {
+
+	var (
+		meta    attachmentMeta
+		fields  = map[string]json.RawMessage{}
+		written int64
+		link    string
+	)
+
+	dec := json.NewDecoder(r)
+
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("bookstack: expected attachment object, got %v", tok)
+	}
+
+	for dec.More() {
+
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key, _ := tok.(string)
+
+		if key != "content" {
+
+			raw := json.RawMessage{}
+
+			if err := dec.Decode(&raw); err != nil {
+				return nil, err
+			}
+
+			fields[key] = raw
+
+			continue
+		}
+
+		if err := meta.apply(fields); err != nil {
+			return nil, err
+		}
+
+		rest := bufio.NewReader(io.MultiReader(dec.Buffered(), r))
+
+		if err := expectByte(rest, ':'); err != nil {
+			return nil, err
+		}
+
+		c, err := nextByte(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case c == '"' && meta.External:
+
+			data, err := io.ReadAll(&jsonString{r: rest})
+			if err != nil {
+				return nil, err
+			}
+
+			link = string(data)
+
+		case c == '"':
+
+			written, err = io.Copy(w, base64.NewDecoder(base64.StdEncoding, &jsonString{r: rest}))
+			if err != nil {
+				return nil, fmt.Errorf("bookstack: decoding attachment content: %w", err)
+			}
+
+		case c == 'n':
+
+			if err := expectLiteral(rest, "ull"); err != nil {
+				return nil, err
+			}
+
+		default:
+			return nil, fmt.Errorf("bookstack: unexpected %q in attachment content", c)
+		}
+
+		c, err = nextByte(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		if c == '}' {
+			break
+		}
+
+		if c != ',' {
+			return nil, fmt.Errorf("bookstack: unexpected %q after attachment content", c)
+		}
+
+		// Reopen the object after content so the remaining fields can be
+		// decoded as before.
+		dec = json.NewDecoder(io.MultiReader(strings.NewReader("{"), rest))
+
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := meta.apply(fields); err != nil {
+		return nil, err
+	}
+
+	if meta.External {
+		return AttachmentLink{ID: meta.ID, Name: meta.Name, URL: link}, nil
+	}
+
+	return AttachmentFile{
+		ID:        meta.ID,
+		Name:      meta.Name,
+		Extension: meta.Extension,
+		Filename:  attachmentFilename(meta.Name, meta.Extension),
+		Size:      written,
+	}, nil
+}
+
+func (m *attachmentMeta) apply(fields map[string]json.RawMessage) error {
+
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, m)
+}
+
+func attachmentFilename(name, extension string) string {
+
+	if extension == "" || strings.EqualFold(path.Ext(name), "."+extension) {
+		return name
+	}
+
+	return name + "." + extension
+}
+
+// nextByte returns the next byte of r that is not whitespace.
+func nextByte(r *bufio.Reader) (byte, error) {
+
+	for {
+
+		c, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		return c, nil
+	}
+}
+
+func expectByte(r *bufio.Reader, want byte) error {
+
+	c, err := nextByte(r)
+	if err != nil {
+		return err
+	}
+
+	if c != want {
+		return fmt.Errorf("bookstack: expected %q in attachment, got %q", want, c)
+	}
+
+	return nil
+}
+
+func expectLiteral(r *bufio.Reader, want string) error {
+
+	got := make([]byte, len(want))
+
+	if _, err := io.ReadFull(r, got); err != nil {
+		return err
+	}
+
+	if string(got) != want {
+		return fmt.Errorf("bookstack: invalid literal in attachment content")
+	}
+
+	return nil
+}
+
+// jsonString reads the unescaped contents of a JSON string, starting after
+// its opening quote and ending at its closing quote.
+type jsonString struct {
+	r       *bufio.Reader
+	pending []byte
+	done    bool
+}
+
+func (s *jsonString) Read(p []byte) (int, error) {
+
+	n := 0
+
+	for n < len(p) {
+
+		if len(s.pending) > 0 {
+			c := copy(p[n:], s.pending)
+			s.pending = s.pending[c:]
+			n += c
+			continue
+		}
+
+		if s.done {
+			break
+		}
+
+		c, err := s.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, err
+		}
+
+		switch c {
+		case '"':
+			s.done = true
+		case '\\':
+			if s.pending, err = s.escape(); err != nil {
+				return n, err
+			}
+		default:
+			p[n] = c
+			n++
+		}
+	}
+
+	if n == 0 && s.done {
+		return 0, io.EOF
+	}
+
+	return n, nil
+}
+
+func (s *jsonString) escape() ([]byte, error) {
+
+	c, err := s.r.ReadByte()
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	switch c {
+	case '"', '\\', '/':
+		return []byte{c}, nil
+	case 'b':
+		return []byte{'\b'}, nil
+	case 'f':
+		return []byte{'\f'}, nil
+	case 'n':
+		return []byte{'\n'}, nil
+	case 'r':
+		return []byte{'\r'}, nil
+	case 't':
+		return []byte{'\t'}, nil
+	case 'u':
+
+		r, err := s.hex()
+		if err != nil {
+			return nil, err
+		}
+
+		if utf16.IsSurrogate(r) {
+			if next, err := s.r.Peek(2); err == nil && string(next) == `\u` {
+
+				s.r.Discard(2)
+
+				low, err := s.hex()
+				if err != nil {
+					return nil, err
+				}
+
+				r = utf16.DecodeRune(r, low)
+			}
+		}
+
+		buf := make([]byte, utf8.UTFMax)
+
+		return buf[:utf8.EncodeRune(buf, r)], nil
+	}
+
+	return nil, fmt.Errorf("bookstack: invalid escape %q in attachment content", c)
+}
+
+func (s *jsonString) hex() (rune, error) {
+
+	digits := make([]byte, 4)
+
+	if _, err := io.ReadFull(s.r, digits); err != nil {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	var r rune
+
+	for _, d := range digits {
+
+		r <<= 4
+
+		switch {
+		case d >= '0' && d <= '9':
+			r |= rune(d - '0')
+		case d >= 'a' && d <= 'f':
+			r |= rune(d - 'a' + 10)
+		case d >= 'A' && d <= 'F':
+			r |= rune(d - 'A' + 10)
+		default:
+			return 0, fmt.Errorf("bookstack: invalid escape in attachment content")
+		}
+	}
+
+	return r, nil
+}
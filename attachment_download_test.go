@@ -0,0 +1,97 @@
+package bookstack_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadAttachment(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Book"})
+	check.NoError(err)
+
+	page, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Page", HTML: "<p>Page</p>"})
+	check.NoError(err)
+
+	contents := bytes.Repeat([]byte("attachment contents\n"), 4096)
+
+	file, err := bk.CreateAttachment(ctx, bookstack.AttachmentParams{
+		Name:       "Notes",
+		UploadedTo: page.ID,
+		FileUpload: &bookstack.Upload{Name: "notes.txt", Reader: bytes.NewReader(contents)},
+	})
+	check.NoError(err)
+
+	link, err := bk.CreateAttachment(ctx, bookstack.AttachmentParams{Name: "Site", UploadedTo: page.ID, Link: "https://example.com/a?b=c&d=e"})
+	check.NoError(err)
+
+	out := bytes.NewBuffer(nil)
+
+	result, err := bk.DownloadAttachment(ctx, file.ID, out)
+	check.NoError(err)
+	check.Equal(bookstack.AttachmentFile{
+		ID:        file.ID,
+		Name:      "Notes",
+		Extension: "txt",
+		Filename:  "Notes.txt",
+		Size:      int64(len(contents)),
+	}, result)
+	check.Equal(contents, out.Bytes())
+
+	out.Reset()
+
+	result, err = bk.DownloadAttachment(ctx, link.ID, out)
+	check.NoError(err)
+	check.Equal(bookstack.AttachmentLink{ID: link.ID, Name: "Site", URL: "https://example.com/a?b=c&d=e"}, result)
+	check.Zero(out.Len())
+
+	_, err = bk.DownloadAttachment(ctx, 99, out)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+}
+
+func TestDownloadAttachmentEscapes(t *testing.T) {
+
+	check := require.New(t)
+
+	// "/??>" encodes to "Lz8/Pg==", which PHP escapes as "Lz8\/Pg==".
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":3,"name":"report.bin","extension":"bin","external":false,"content":"Lz8\/Pg==","links":{"html":"<a href=\"x\">report<\/a>"},"order":1}`))
+	}))
+	defer srv.Close()
+
+	out := bytes.NewBuffer(nil)
+
+	result, err := bookstack.New(bookstack.SetURL(srv.URL)).DownloadAttachment(context.Background(), 3, out)
+	check.NoError(err)
+	check.Equal("/??>", out.String())
+
+	file, ok := result.(bookstack.AttachmentFile)
+	check.True(ok)
+	check.Equal("report.bin", file.Filename)
+	check.Equal(int64(4), file.Size)
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":3,"external":false,"content":"not base64!"}`))
+	})
+
+	_, err = bookstack.New(bookstack.SetURL(srv.URL)).DownloadAttachment(context.Background(), 3, out)
+	check.Error(err)
+	check.True(strings.Contains(err.Error(), "decoding attachment content"))
+}
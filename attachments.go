@@ -6,10 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 )
@@ -51,62 +48,31 @@ type AttachmentParams struct {
 	UploadedTo int    `json:"uploaded_to,omitempty"`
 	File       string `json:"file,omitempty"`
 	Link       string `json:"link,omitempty"`
+	// FileUpload is read in place of File when set.
+	FileUpload *Upload `json:"-"`
+
+	method string
 }
 
 func (a AttachmentParams) Form() (string, io.Reader, error) {
 
-	if a.File != "" {
-
-		body := bytes.NewBuffer(nil)
-		writer := multipart.NewWriter(body)
+	if a.File != "" || a.FileUpload != nil {
 
-		defer writer.Close()
+		fields := overrideFields(a.method)
 
 		if a.Name != "" {
-
-			if err := writer.WriteField("name", a.Name); err != nil {
-				return "", nil, err
-			}
-
+			fields = append(fields, formField{"name", a.Name})
 		}
 
 		if a.Link != "" {
-
-			if err := writer.WriteField("link", a.Link); err != nil {
-				return "", nil, err
-			}
-
+			fields = append(fields, formField{"link", a.Link})
 		}
 
 		if a.UploadedTo != 0 {
-
-			if err := writer.WriteField("uploaded_to", strconv.Itoa(a.UploadedTo)); err != nil {
-				return "", nil, err
-			}
-
-		}
-
-		img, err := writer.CreateFormFile("file", filepath.Base(a.File))
-		if err != nil {
-			return "", nil, err
-		}
-
-		f, err := os.Open(a.File)
-		if err != nil {
-			return "", nil, err
-		}
-
-		defer f.Close()
-
-		if _, err := io.Copy(img, f); err != nil {
-			return "", nil, err
-		}
-
-		if err := writer.Close(); err != nil {
-			return "", nil, err
+			fields = append(fields, formField{"uploaded_to", strconv.Itoa(a.UploadedTo)})
 		}
 
-		return writer.FormDataContentType(), bytes.NewReader(body.Bytes()), nil
+		return multipartForm(fields, multipartFile{field: "file", path: a.File, upload: a.FileUpload})
 
 	}
 
@@ -119,6 +85,14 @@ func (a AttachmentParams) Form() (string, io.Reader, error) {
 
 }
 
+func (a AttachmentParams) replayable() bool {
+	return a.FileUpload.replayable()
+}
+
+func (a AttachmentParams) override() string {
+	return a.method
+}
+
 // ListAttachments will return the attachments that match the given params.
 func (b *Bookstack) ListAttachments(ctx context.Context, params *QueryParams) ([]Attachment, error) {
 
@@ -130,6 +104,18 @@ func (b *Bookstack) ListAttachments(ctx context.Context, params *QueryParams) ([
 	return ParseMultiple[[]Attachment](resp)
 }
 
+// ListAttachmentsResult will return a page of the attachments that match the given params,
+// along with the total and the pagination applied.
+func (b *Bookstack) ListAttachmentsResult(ctx context.Context, params *QueryParams) (ListResult[Attachment], error) {
+	return List[Attachment](ctx, b, "/attachments", params)
+}
+
+// AllAttachments will return every attachment that matches the given params, following
+// pagination. The Count of params sets the page size.
+func (b *Bookstack) AllAttachments(ctx context.Context, params *QueryParams) ([]Attachment, error) {
+	return Paginate[Attachment](ctx, b, "/attachments", params).All()
+}
+
 // GetAttachment will return a single attachment that matches id.
 func (b *Bookstack) GetAttachment(ctx context.Context, id int) (AttachmentDetailed, error) {
 
@@ -156,7 +142,10 @@ func (b *Bookstack) CreateAttachment(ctx context.Context, params AttachmentParam
 // UpdateAttachment will update a attachment with the given params.
 func (b *Bookstack) UpdateAttachment(ctx context.Context, id int, params AttachmentParams) (Attachment, error) {
 
-	resp, err := b.request(ctx, http.MethodPut, fmt.Sprintf("/attachments/%d", id), params)
+	var method string
+	method, params.method = overrideMethod(http.MethodPut, params.File != "" || params.FileUpload != nil)
+
+	resp, err := b.request(ctx, method, fmt.Sprintf("/attachments/%d", id), params)
 	if err != nil {
 		return Attachment{}, err
 	}
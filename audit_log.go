@@ -0,0 +1,324 @@
+package bookstack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AuditEvent is the type of activity recorded in the audit log.
+type AuditEvent string
+
+const (
+	AuditPageCreate  AuditEvent = "page_create"
+	AuditPageUpdate  AuditEvent = "page_update"
+	AuditPageDelete  AuditEvent = "page_delete"
+	AuditPageRestore AuditEvent = "page_restore"
+	AuditPageMove    AuditEvent = "page_move"
+
+	AuditChapterCreate AuditEvent = "chapter_create"
+	AuditChapterUpdate AuditEvent = "chapter_update"
+	AuditChapterDelete AuditEvent = "chapter_delete"
+	AuditChapterMove   AuditEvent = "chapter_move"
+
+	AuditBookCreate AuditEvent = "book_create"
+	AuditBookUpdate AuditEvent = "book_update"
+	AuditBookDelete AuditEvent = "book_delete"
+	AuditBookSort   AuditEvent = "book_sort"
+
+	AuditShelfCreate AuditEvent = "bookshelf_create"
+	AuditShelfUpdate AuditEvent = "bookshelf_update"
+	AuditShelfDelete AuditEvent = "bookshelf_delete"
+
+	AuditRevisionRestore AuditEvent = "revision_restore"
+	AuditRevisionDelete  AuditEvent = "revision_delete"
+
+	AuditCommentCreate AuditEvent = "comment_create"
+	AuditCommentUpdate AuditEvent = "comment_update"
+	AuditCommentDelete AuditEvent = "comment_delete"
+
+	AuditPermissionsUpdate AuditEvent = "permissions_update"
+
+	AuditSettingsUpdate       AuditEvent = "settings_update"
+	AuditMaintenanceActionRun AuditEvent = "maintenance_action_run"
+
+	AuditRecycleBinEmpty   AuditEvent = "recycle_bin_empty"
+	AuditRecycleBinRestore AuditEvent = "recycle_bin_restore"
+	AuditRecycleBinDestroy AuditEvent = "recycle_bin_destroy"
+
+	AuditUserCreate AuditEvent = "user_create"
+	AuditUserUpdate AuditEvent = "user_update"
+	AuditUserDelete AuditEvent = "user_delete"
+
+	AuditAPITokenCreate AuditEvent = "api_token_create"
+	AuditAPITokenUpdate AuditEvent = "api_token_update"
+	AuditAPITokenDelete AuditEvent = "api_token_delete"
+
+	AuditRoleCreate AuditEvent = "role_create"
+	AuditRoleUpdate AuditEvent = "role_update"
+	AuditRoleDelete AuditEvent = "role_delete"
+
+	AuditAuthLogin                AuditEvent = "auth_login"
+	AuditAuthRegister             AuditEvent = "auth_register"
+	AuditAuthPasswordResetRequest AuditEvent = "auth_password_reset_request"
+	AuditAuthPasswordResetUpdate  AuditEvent = "auth_password_reset_update"
+
+	AuditMFASetupMethod  AuditEvent = "mfa_setup_method"
+	AuditMFARemoveMethod AuditEvent = "mfa_remove_method"
+
+	AuditWebhookCreate AuditEvent = "webhook_create"
+	AuditWebhookUpdate AuditEvent = "webhook_update"
+	AuditWebhookDelete AuditEvent = "webhook_delete"
+)
+
+type AuditLogEntry struct {
+	ID           int          `json:"id,omitempty"`
+	Event        AuditEvent   `json:"type,omitempty"`
+	Detail       string       `json:"detail,omitempty"`
+	UserID       int          `json:"user_id,omitempty"`
+	LoggableID   int          `json:"loggable_id,omitempty"`
+	LoggableType ContentType  `json:"loggable_type,omitempty"`
+	IP           string       `json:"ip,omitempty"`
+	CreatedAt    time.Time    `json:"created_at,omitempty"`
+	User         AuditLogUser `json:"user,omitempty"`
+}
+
+type AuditLogUser struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// AuditLogDateFormat is the format of dates in audit log filters.
+const AuditLogDateFormat = "2006-01-02 15:04:05"
+
+// AuditLogParams filters the audit log. Zero values are ignored.
+type AuditLogParams struct {
+	// From and To limit entries to those created in the range, inclusive.
+	From time.Time
+	To   time.Time
+
+	Event        AuditEvent
+	UserID       int
+	LoggableType ContentType
+	LoggableID   int
+	IP           string
+
+	Count          int
+	Offset         int
+	SortDescending bool
+}
+
+func (a *AuditLogParams) params() *QueryParams {
+
+	q := &QueryParams{SortField: "id"}
+
+	if a == nil {
+		return q
+	}
+
+	q.Count = a.Count
+	q.Offset = a.Offset
+	q.SortDescending = a.SortDescending
+
+	if !a.From.IsZero() {
+		q.Filters = append(q.Filters, Filter{Field: "created_at", Operator: FilterGreaterOrEqual, Value: a.From.UTC().Format(AuditLogDateFormat)})
+	}
+
+	if !a.To.IsZero() {
+		q.Filters = append(q.Filters, Filter{Field: "created_at", Operator: FilterLessOrEqual, Value: a.To.UTC().Format(AuditLogDateFormat)})
+	}
+
+	if a.Event != "" {
+		q.Filters = append(q.Filters, Filter{Field: "type", Value: string(a.Event)})
+	}
+
+	if a.UserID != 0 {
+		q.Filters = append(q.Filters, Filter{Field: "user_id", Value: strconv.Itoa(a.UserID)})
+	}
+
+	if a.LoggableType != "" {
+		q.Filters = append(q.Filters, Filter{Field: "loggable_type", Value: string(a.LoggableType)})
+	}
+
+	if a.LoggableID != 0 {
+		q.Filters = append(q.Filters, Filter{Field: "loggable_id", Value: strconv.Itoa(a.LoggableID)})
+	}
+
+	if a.IP != "" {
+		q.Filters = append(q.Filters, Filter{Field: "ip", Value: a.IP})
+	}
+
+	return q
+}
+
+// ListAuditLog will return the audit log entries that match the given params.
+func (b *Bookstack) ListAuditLog(ctx context.Context, params *AuditLogParams) ([]AuditLogEntry, error) {
+
+	if err := b.require(ctx, CapabilityAuditLog); err != nil {
+		return nil, err
+	}
+
+	resp, err := b.request(ctx, http.MethodGet, params.params().String("/audit-log"), blank{})
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseMultiple[[]AuditLogEntry](resp)
+}
+
+// ListAuditLogResult will return a page of the audit log entries that match
+// the given params, along with the total and the pagination applied.
+func (b *Bookstack) ListAuditLogResult(ctx context.Context, params *AuditLogParams) (ListResult[AuditLogEntry], error) {
+
+	if err := b.require(ctx, CapabilityAuditLog); err != nil {
+		return ListResult[AuditLogEntry]{}, err
+	}
+
+	return List[AuditLogEntry](ctx, b, "/audit-log", params.params())
+}
+
+// AllAuditLog will return every audit log entry that matches the given
+// params, following pagination. The Count of params sets the page size.
+func (b *Bookstack) AllAuditLog(ctx context.Context, params *AuditLogParams) ([]AuditLogEntry, error) {
+
+	if err := b.require(ctx, CapabilityAuditLog); err != nil {
+		return nil, err
+	}
+
+	return Paginate[AuditLogEntry](ctx, b, "/audit-log", params.params()).All()
+}
+
+// AuditLogCursor marks a position in the audit log. It can be stored and
+// passed to TailAuditLog to resume after a restart.
+type AuditLogCursor struct {
+	// ID is the id of the last entry seen.
+	ID int `json:"id"`
+}
+
+// AuditLogTail streams new audit log entries. Entries are sent on C, which
+// is closed when the context is done or polling fails.
+type AuditLogTail struct {
+	C <-chan AuditLogEntry
+
+	mu     sync.Mutex
+	cursor AuditLogCursor
+	err    error
+}
+
+// Cursor returns the position after the last entry sent on C.
+func (t *AuditLogTail) Cursor() AuditLogCursor {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.cursor
+}
+
+// Err returns the error that stopped the tail, once C is closed. It is nil
+// when the tail stopped because its context was done.
+func (t *AuditLogTail) Err() error {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.err
+}
+
+// TailAuditLog polls the audit log every interval and sends entries created
+// after cursor, oldest first. A zero cursor starts from the latest entry, so
+// only entries created from now on are sent. Only the filters of params are
+// used; From and To, Count, Offset and sorting are ignored. An interval that
+// is not positive stops the tail at once with an error.
+func (b *Bookstack) TailAuditLog(ctx context.Context, cursor AuditLogCursor, interval time.Duration, params *AuditLogParams) *AuditLogTail {
+
+	c := make(chan AuditLogEntry)
+
+	t := &AuditLogTail{C: c, cursor: cursor}
+
+	filter := AuditLogParams{}
+	if params != nil {
+		filter = AuditLogParams{
+			Event:        params.Event,
+			UserID:       params.UserID,
+			LoggableType: params.LoggableType,
+			LoggableID:   params.LoggableID,
+			IP:           params.IP,
+		}
+	}
+
+	go func() {
+
+		defer close(c)
+
+		err := t.run(ctx, b, c, interval, filter)
+
+		if err != nil && ctx.Err() == nil {
+			t.mu.Lock()
+			t.err = err
+			t.mu.Unlock()
+		}
+	}()
+
+	return t
+}
+
+func (t *AuditLogTail) run(ctx context.Context, b *Bookstack, c chan<- AuditLogEntry, interval time.Duration, filter AuditLogParams) error {
+
+	if interval <= 0 {
+		return fmt.Errorf("bookstack: audit log tail interval must be positive, got %v", interval)
+	}
+
+	if err := b.require(ctx, CapabilityAuditLog); err != nil {
+		return err
+	}
+
+	if t.Cursor().ID == 0 {
+
+		latest, err := b.ListAuditLog(ctx, &AuditLogParams{Count: 1, SortDescending: true})
+		if err != nil {
+			return err
+		}
+
+		if len(latest) > 0 {
+			t.mu.Lock()
+			t.cursor.ID = latest[0].ID
+			t.mu.Unlock()
+		}
+	}
+
+	for {
+
+		params := filter.params()
+		params.Filters = append(params.Filters, Filter{Field: "id", Operator: FilterGreaterThan, Value: strconv.Itoa(t.Cursor().ID)})
+
+		p := Paginate[AuditLogEntry](ctx, b, "/audit-log", params)
+
+		for p.Next() {
+
+			entry := p.Item()
+
+			select {
+			case c <- entry:
+			case <-ctx.Done():
+				p.Close()
+				return ctx.Err()
+			}
+
+			t.mu.Lock()
+			t.cursor.ID = entry.ID
+			t.mu.Unlock()
+		}
+
+		if err := p.Err(); err != nil {
+			return err
+		}
+
+		if err := sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package bookstack_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	day := func(d int) time.Time {
+		return time.Date(2023, time.March, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	srv.AddAuditLogEntry(bookstack.AuditLogEntry{Event: bookstack.AuditAuthLogin, UserID: 1, CreatedAt: day(1)})
+	srv.AddAuditLogEntry(bookstack.AuditLogEntry{Event: bookstack.AuditAuthLogin, UserID: 2, CreatedAt: day(2)})
+	srv.AddAuditLogEntry(bookstack.AuditLogEntry{Event: bookstack.AuditSettingsUpdate, UserID: 1, Detail: "app-name", CreatedAt: day(3)})
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Handbook"})
+	check.NoError(err)
+
+	entries, err := bk.ListAuditLog(ctx, nil)
+	check.NoError(err)
+	check.Len(entries, 4)
+	check.Equal(bookstack.AuditBookCreate, entries[3].Event)
+	check.Equal(bookstack.ContentBook, entries[3].LoggableType)
+	check.Equal(book.ID, entries[3].LoggableID)
+	check.Equal("Admin", entries[3].User.Name)
+
+	logins, err := bk.ListAuditLog(ctx, &bookstack.AuditLogParams{Event: bookstack.AuditAuthLogin})
+	check.NoError(err)
+	check.Len(logins, 2)
+
+	guest, err := bk.ListAuditLog(ctx, &bookstack.AuditLogParams{Event: bookstack.AuditAuthLogin, UserID: 2})
+	check.NoError(err)
+	check.Len(guest, 1)
+	check.Equal(day(2), guest[0].CreatedAt)
+
+	ranged, err := bk.ListAuditLog(ctx, &bookstack.AuditLogParams{From: day(2), To: day(3)})
+	check.NoError(err)
+	check.Len(ranged, 2)
+	check.Equal(bookstack.AuditSettingsUpdate, ranged[1].Event)
+
+	all, err := bk.AllAuditLog(ctx, &bookstack.AuditLogParams{Count: 1, SortDescending: true})
+	check.NoError(err)
+	check.Len(all, 4)
+	check.Equal(bookstack.AuditBookCreate, all[0].Event)
+}
+
+func TestTailAuditLog(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	srv.AddAuditLogEntry(bookstack.AuditLogEntry{Event: bookstack.AuditAuthLogin})
+
+	tailCtx, stop := context.WithCancel(ctx)
+
+	tail := bk.TailAuditLog(tailCtx, bookstack.AuditLogCursor{}, 10*time.Millisecond, &bookstack.AuditLogParams{LoggableType: bookstack.ContentBook})
+
+	// Give the tail time to seed its cursor from the existing entry.
+	time.Sleep(50 * time.Millisecond)
+
+	first, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "First"})
+	check.NoError(err)
+
+	srv.AddAuditLogEntry(bookstack.AuditLogEntry{Event: bookstack.AuditAuthLogin})
+
+	entry := <-tail.C
+	check.Equal(bookstack.AuditBookCreate, entry.Event)
+	check.Equal(first.ID, entry.LoggableID)
+
+	cursor := tail.Cursor()
+	check.Equal(entry.ID, cursor.ID)
+
+	stop()
+
+	for range tail.C {
+	}
+
+	check.NoError(tail.Err())
+
+	second, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Second"})
+	check.NoError(err)
+
+	resumed := bk.TailAuditLog(ctx, cursor, 10*time.Millisecond, &bookstack.AuditLogParams{LoggableType: bookstack.ContentBook})
+
+	entry = <-resumed.C
+	check.Equal(bookstack.AuditBookCreate, entry.Event)
+	check.Equal(second.ID, entry.LoggableID)
+}
+
+func TestTailAuditLogError(t *testing.T) {
+
+	check := require.New(t)
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client(bookstack.SetToken("wrong", "token"), bookstack.SetRetryPolicy(bookstack.RetryPolicy{}))
+
+	tail := bk.TailAuditLog(context.Background(), bookstack.AuditLogCursor{}, time.Millisecond, nil)
+
+	for range tail.C {
+	}
+
+	check.ErrorIs(tail.Err(), bookstack.ErrUnauthorized)
+
+	tail = bk.TailAuditLog(context.Background(), bookstack.AuditLogCursor{}, 0, nil)
+
+	for range tail.C {
+	}
+
+	check.ErrorContains(tail.Err(), "interval must be positive")
+}
@@ -0,0 +1,296 @@
+package bookstack
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// BookContent is an item in the contents of a book, as returned with
+// BookDetailed. Chapters hold their pages in Pages.
+type BookContent struct {
+	ID        int           `json:"id,omitempty"`
+	Name      string        `json:"name,omitempty"`
+	Slug      string        `json:"slug,omitempty"`
+	BookID    int           `json:"book_id,omitempty"`
+	ChapterID int           `json:"chapter_id,omitempty"`
+	Priority  int           `json:"priority,omitempty"`
+	Type      ContentType   `json:"type,omitempty"`
+	URL       string        `json:"url,omitempty"`
+	Draft     bool          `json:"draft,omitempty"`
+	Template  bool          `json:"template,omitempty"`
+	CreatedAt time.Time     `json:"created_at,omitempty"`
+	UpdatedAt time.Time     `json:"updated_at,omitempty"`
+	Pages     []BookContent `json:"pages,omitempty"`
+}
+
+// SkipChapter can be returned by a WalkFunc to skip the pages of the chapter
+// it was called with, or the rest of the chapter of the page it was called
+// with.
+var SkipChapter = errors.New("skip this chapter")
+
+// Node is a ChapterNode or a PageNode in a BookTree.
+type Node interface {
+	node()
+}
+
+// ChapterNode is a chapter in a BookTree, with its pages sorted by priority.
+type ChapterNode struct {
+	ID       int
+	Name     string
+	Slug     string
+	Priority int
+	Pages    []*PageNode
+}
+
+// PageNode is a page in a BookTree. Chapter is nil for pages that are directly
+// in the book.
+type PageNode struct {
+	ID       int
+	Name     string
+	Slug     string
+	Priority int
+	Draft    bool
+	Template bool
+	Chapter  *ChapterNode
+}
+
+func (*ChapterNode) node() {}
+
+func (*PageNode) node() {}
+
+// BookTree is the hierarchy of a book: its chapters and the pages directly in
+// it, sorted together by priority, as they are shown in BookStack.
+type BookTree struct {
+	Book     BookDetailed
+	Contents []Node
+}
+
+// WalkFunc is called by Walk for each node of a tree.
+type WalkFunc func(n Node) error
+
+// Walk calls fn for each node of the tree in reading order, calling it for a
+// chapter before its pages. It stops at the first error from fn, other than
+// SkipChapter, and returns it.
+func (t *BookTree) Walk(fn WalkFunc) error {
+
+	for _, n := range t.Contents {
+
+		err := fn(n)
+
+		if err == SkipChapter {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		chapter, ok := n.(*ChapterNode)
+		if !ok {
+			continue
+		}
+
+		for _, p := range chapter.Pages {
+
+			err := fn(p)
+
+			if err == SkipChapter {
+				break
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// FindBySlug will return the first chapter or page in reading order with the
+// given slug.
+func (t *BookTree) FindBySlug(slug string) (Node, bool) {
+
+	var found Node
+
+	t.Walk(func(n Node) error {
+
+		switch x := n.(type) {
+		case *ChapterNode:
+			if x.Slug == slug {
+				found = x
+			}
+		case *PageNode:
+			if x.Slug == slug {
+				found = x
+			}
+		}
+
+		if found != nil {
+			return errStopWalk
+		}
+
+		return nil
+	})
+
+	return found, found != nil
+}
+
+var errStopWalk = errors.New("stop walk")
+
+// Flatten returns every page of the tree in reading order.
+func (t *BookTree) Flatten() []*PageNode {
+
+	pages := []*PageNode{}
+
+	t.Walk(func(n Node) error {
+
+		if p, ok := n.(*PageNode); ok {
+			pages = append(pages, p)
+		}
+
+		return nil
+	})
+
+	return pages
+}
+
+// NewBookTree builds the tree of a book from its contents.
+func NewBookTree(book BookDetailed) *BookTree {
+
+	tree := &BookTree{Book: book, Contents: []Node{}}
+
+	for _, item := range sortContents(book.Contents) {
+
+		switch item.Type {
+		case ContentChapter:
+
+			chapter := &ChapterNode{ID: item.ID, Name: item.Name, Slug: item.Slug, Priority: item.Priority, Pages: []*PageNode{}}
+
+			for _, p := range sortContents(item.Pages) {
+				page := newPageNode(p)
+				page.Chapter = chapter
+				chapter.Pages = append(chapter.Pages, page)
+			}
+
+			tree.Contents = append(tree.Contents, chapter)
+
+		case ContentPage:
+			tree.Contents = append(tree.Contents, newPageNode(item))
+		}
+	}
+
+	return tree
+}
+
+func newPageNode(item BookContent) *PageNode {
+	return &PageNode{
+		ID:       item.ID,
+		Name:     item.Name,
+		Slug:     item.Slug,
+		Priority: item.Priority,
+		Draft:    item.Draft,
+		Template: item.Template,
+	}
+}
+
+// sortContents returns a copy of items sorted by priority, keeping the order
+// of items with the same priority.
+func sortContents(items []BookContent) []BookContent {
+
+	sorted := append([]BookContent(nil), items...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+
+	return sorted
+}
+
+// GetBookTree will return the chapters and pages of the book that matches
+// id as a tree. Servers that leave the contents out of books are read with
+// the chapter and page list endpoints instead.
+func (b *Bookstack) GetBookTree(ctx context.Context, id int) (*BookTree, error) {
+
+	book, err := b.GetBook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if book.Contents == nil {
+
+		contents, err := b.listBookContents(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		book.Contents = contents
+	}
+
+	return NewBookTree(book), nil
+}
+
+// listBookContents builds the contents of a book from the chapter and page
+// list endpoints.
+func (b *Bookstack) listBookContents(ctx context.Context, id int) ([]BookContent, error) {
+
+	params := &QueryParams{FilterField: "book_id", FilterValue: strconv.Itoa(id)}
+
+	chapters, err := b.AllChapters(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := b.AllPages(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := []BookContent{}
+	index := map[int]int{}
+
+	for _, c := range chapters {
+
+		index[c.ID] = len(contents)
+
+		contents = append(contents, BookContent{
+			ID:        c.ID,
+			Name:      c.Name,
+			Slug:      c.Slug,
+			BookID:    c.BookID,
+			Priority:  c.Priority,
+			Type:      ContentChapter,
+			UpdatedAt: c.UpdatedAt,
+			Pages:     []BookContent{},
+		})
+	}
+
+	for _, p := range pages {
+
+		item := BookContent{
+			ID:        p.ID,
+			Name:      p.Name,
+			Slug:      p.Slug,
+			BookID:    p.BookID,
+			ChapterID: p.ChapterID,
+			Priority:  p.Priority,
+			Type:      ContentPage,
+			Draft:     p.Draft,
+			Template:  p.Template,
+			CreatedAt: p.CreatedAt,
+			UpdatedAt: p.UpdatedAt,
+		}
+
+		if i, ok := index[p.ChapterID]; ok && p.ChapterID != 0 {
+			contents[i].Pages = append(contents[i].Pages, item)
+			continue
+		}
+
+		contents = append(contents, item)
+	}
+
+	return contents, nil
+}
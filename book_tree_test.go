@@ -0,0 +1,117 @@
+package bookstack_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookTree(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Manual"})
+	check.NoError(err)
+
+	intro, err := bk.CreateChapter(ctx, bookstack.ChapterParams{BookID: book.ID, Name: "Intro"})
+	check.NoError(err)
+
+	_, err = bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Overview", HTML: "<p>Overview</p>"})
+	check.NoError(err)
+
+	usage, err := bk.CreateChapter(ctx, bookstack.ChapterParams{BookID: book.ID, Name: "Usage"})
+	check.NoError(err)
+
+	for _, name := range []string{"Install", "Setup"} {
+		_, err = bk.CreatePage(ctx, bookstack.PageParams{ChapterID: intro.ID, Name: name, HTML: "<p>" + name + "</p>"})
+		check.NoError(err)
+	}
+
+	_, err = bk.CreatePage(ctx, bookstack.PageParams{ChapterID: usage.ID, Name: "Commands", HTML: "<p>Commands</p>"})
+	check.NoError(err)
+
+	names := func(tree *bookstack.BookTree) []string {
+
+		list := []string{}
+
+		tree.Walk(func(n bookstack.Node) error {
+
+			switch x := n.(type) {
+			case *bookstack.ChapterNode:
+				list = append(list, "chapter:"+x.Name)
+			case *bookstack.PageNode:
+				list = append(list, "page:"+x.Name)
+			}
+
+			return nil
+		})
+
+		return list
+	}
+
+	want := []string{"chapter:Intro", "page:Install", "page:Setup", "page:Overview", "chapter:Usage", "page:Commands"}
+
+	detailed, err := bk.GetBook(ctx, book.ID)
+	check.NoError(err)
+	check.Len(detailed.Contents, 3)
+
+	tree, err := bk.GetBookTree(ctx, book.ID)
+	check.NoError(err)
+	check.Equal("Manual", tree.Book.Name)
+	check.Equal(want, names(tree))
+
+	pages := tree.Flatten()
+	check.Len(pages, 4)
+	check.Equal("Install", pages[0].Name)
+	check.Equal(intro.ID, pages[0].Chapter.ID)
+	check.Nil(pages[2].Chapter)
+
+	node, ok := tree.FindBySlug("setup")
+	check.True(ok)
+	check.Equal("Setup", node.(*bookstack.PageNode).Name)
+
+	node, ok = tree.FindBySlug("usage")
+	check.True(ok)
+	check.Len(node.(*bookstack.ChapterNode).Pages, 1)
+
+	_, ok = tree.FindBySlug("missing")
+	check.False(ok)
+
+	skipped := []string{}
+
+	check.NoError(tree.Walk(func(n bookstack.Node) error {
+
+		if c, ok := n.(*bookstack.ChapterNode); ok && c.Name == "Intro" {
+			return bookstack.SkipChapter
+		}
+
+		if p, ok := n.(*bookstack.PageNode); ok {
+			skipped = append(skipped, p.Name)
+		}
+
+		return nil
+	}))
+	check.Equal([]string{"Overview", "Commands"}, skipped)
+
+	srv.SetVersion("")
+
+	fallback := srv.Client()
+
+	detailed, err = fallback.GetBook(ctx, book.ID)
+	check.NoError(err)
+	check.Nil(detailed.Contents)
+
+	tree, err = fallback.GetBookTree(ctx, book.ID)
+	check.NoError(err)
+	check.Equal(want, names(tree))
+}
@@ -6,10 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"time"
 )
 
@@ -37,62 +34,39 @@ type BookDetailed struct {
 	OwnedBy     OwnedBy   `json:"owned_by,omitempty"`
 	Tags        []Tag     `json:"tags,omitempty"`
 	Cover       Cover     `json:"cover,omitempty"`
+	// Contents are the chapters and pages of the book. They are nil when the
+	// server leaves them out.
+	Contents []BookContent `json:"contents,omitempty"`
 }
 
 type BookParams struct {
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
-	Image       string `json:"image,omitempty"`
+	Name        string      `json:"name,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Tags        []TagParams `json:"tags,omitempty"`
+	Image       string      `json:"image,omitempty"`
+	// ImageUpload is read in place of Image when set.
+	ImageUpload *Upload `json:"-"`
+
+	method string
 }
 
 func (bp BookParams) Form() (string, io.Reader, error) {
 
-	if bp.Image != "" {
+	if bp.Image != "" || bp.ImageUpload != nil {
 
-		body := bytes.NewBuffer(nil)
-		writer := multipart.NewWriter(body)
-
-		defer writer.Close()
+		fields := overrideFields(bp.method)
 
 		if bp.Name != "" {
-
-			if err := writer.WriteField("name", bp.Name); err != nil {
-				return "", nil, err
-			}
-
+			fields = append(fields, formField{"name", bp.Name})
 		}
 
-		// TODO: Tags
+		fields = append(fields, tagFields(bp.Tags)...)
 
 		if bp.Description != "" {
-
-			if err := writer.WriteField("description", bp.Description); err != nil {
-				return "", nil, err
-			}
-
-		}
-
-		img, err := writer.CreateFormFile("image", filepath.Base(bp.Image))
-		if err != nil {
-			return "", nil, err
-		}
-
-		f, err := os.Open(bp.Image)
-		if err != nil {
-			return "", nil, err
+			fields = append(fields, formField{"description", bp.Description})
 		}
 
-		defer f.Close()
-
-		if _, err := io.Copy(img, f); err != nil {
-			return "", nil, err
-		}
-
-		if err := writer.Close(); err != nil {
-			return "", nil, err
-		}
-
-		return writer.FormDataContentType(), bytes.NewReader(body.Bytes()), nil
+		return multipartForm(fields, multipartFile{field: "image", path: bp.Image, upload: bp.ImageUpload})
 
 	}
 
@@ -105,6 +79,14 @@ func (bp BookParams) Form() (string, io.Reader, error) {
 
 }
 
+func (bp BookParams) replayable() bool {
+	return bp.ImageUpload.replayable()
+}
+
+func (bp BookParams) override() string {
+	return bp.method
+}
+
 // ListBooks will return the books that match the given params.
 func (b *Bookstack) ListBooks(ctx context.Context, params *QueryParams) ([]Book, error) {
 
@@ -116,6 +98,18 @@ func (b *Bookstack) ListBooks(ctx context.Context, params *QueryParams) ([]Book,
 	return ParseMultiple[[]Book](resp)
 }
 
+// ListBooksResult will return a page of the books that match the given params,
+// along with the total and the pagination applied.
+func (b *Bookstack) ListBooksResult(ctx context.Context, params *QueryParams) (ListResult[Book], error) {
+	return List[Book](ctx, b, "/books", params)
+}
+
+// AllBooks will return every book that matches the given params, following
+// pagination. The Count of params sets the page size.
+func (b *Bookstack) AllBooks(ctx context.Context, params *QueryParams) ([]Book, error) {
+	return Paginate[Book](ctx, b, "/books", params).All()
+}
+
 // GetBook will return a single book that matches id.
 func (b *Bookstack) GetBook(ctx context.Context, id int) (BookDetailed, error) {
 
@@ -142,7 +136,10 @@ func (b *Bookstack) CreateBook(ctx context.Context, params BookParams) (Book, er
 // UpdateBook will update a book with the given params.
 func (b *Bookstack) UpdateBook(ctx context.Context, id int, params BookParams) (Book, error) {
 
-	resp, err := b.request(ctx, http.MethodPut, fmt.Sprintf("/books/%d", id), params)
+	var method string
+	method, params.method = overrideMethod(http.MethodPut, params.Image != "" || params.ImageUpload != nil)
+
+	resp, err := b.request(ctx, method, fmt.Sprintf("/books/%d", id), params)
 	if err != nil {
 		return Book{}, err
 	}
@@ -24,7 +24,12 @@ type Bookstack struct {
 	tokenSecret string
 	limit       ratelimit.Limiter
 	log         *log.Logger
-	insecure    bool
+	retry       RetryPolicy
+	client      *http.Client
+	transport   http.RoundTripper
+	tls         []func(*tls.Config)
+	http        *http.Client
+	server      serverVersion
 }
 
 type Option func(*Bookstack)
@@ -60,86 +65,131 @@ func New(opts ...Option) *Bookstack {
 	b := &Bookstack{
 		limit: ratelimit.New(180),
 		log:   log.New(ioutil.Discard, "", 0),
+		retry: DefaultRetryPolicy,
 	}
 
 	for _, opt := range opts {
 		opt(b)
 	}
 
+	b.http = b.httpClient()
+
 	return b
 }
 
+// URL returns the url of the site the client controls.
+func (b *Bookstack) URL() string {
+	return b.url
+}
+
 func (b *Bookstack) authorization() string {
 	return fmt.Sprintf("Token %s:%s", b.tokenID, b.tokenSecret)
 }
 
+// Form encodes a request body. It is called once for every attempt of a
+// request, so it must return a fresh reader each time it is called.
 type Form interface {
 	Form() (string, io.Reader, error)
 }
 
 func (b *Bookstack) request(ctx context.Context, method, query string, data Form) ([]byte, error) {
 
-	b.limit.Take()
+	resp, err := b.do(ctx, method, query, data)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// do sends a request, retrying transient failures according to the retry
+// policy, and returns the first successful response. Failed responses are
+// returned as an *APIError.
+func (b *Bookstack) do(ctx context.Context, method, query string, data Form) (*http.Response, error) {
 
 	url := fmt.Sprintf("%s/api/%s", strings.TrimRight(b.url, "/"), strings.TrimLeft(query, "/"))
 
-	client := http.DefaultClient
+	attempts := 1
+	if b.retry.allows(effectiveMethod(method, data)) && b.retry.MaxAttempts > 1 && replayable(data) {
+		attempts = b.retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+
+		b.limit.Take()
 
-	if b.insecure {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
+		contentType, reader, err := data.Form()
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	contentType, reader, err := data.Form()
-	if err != nil {
-		return nil, err
-	}
+		req, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			if c, ok := reader.(io.Closer); ok {
+				c.Close()
+			}
+			return nil, err
+		}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, reader)
-	if err != nil {
-		return nil, err
-	}
+		if body, ok := reader.(*multipartBody); ok && body.contentLength() >= 0 {
+			req.ContentLength = body.contentLength()
+		}
 
-	req.Header.Add("Authorization", b.authorization())
-	if contentType != "" {
-		req.Header.Add("Content-Type", contentType)
-	}
+		req.Header.Add("Authorization", b.authorization())
+		if contentType != "" {
+			req.Header.Add("Content-Type", contentType)
+		}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
+		resp, err := b.http.Do(req)
+		if err != nil {
 
-	defer resp.Body.Close()
+			if attempt >= attempts || !retryableErr(err) {
+				return nil, err
+			}
 
-	raw, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+			delay := b.retry.wait(nil, attempt)
+			b.log.Printf("bookstack: %s %s failed, retrying in %s: %v", method, req.URL.Path, delay, err)
 
-	if resp.StatusCode >= http.StatusOK && resp.StatusCode <= http.StatusIMUsed {
-		return raw, nil
-	}
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
 
-	msg := Response{}
+			continue
+		}
 
-	if err := json.Unmarshal(raw, &msg); err != nil {
-		return nil, err
-	}
+		if resp.StatusCode >= http.StatusOK && resp.StatusCode <= http.StatusIMUsed {
+			return resp, nil
+		}
+
+		raw, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
 
-	return nil, msg.Error()
+		apiErr := newAPIError(req, resp, raw)
 
+		if attempt >= attempts || !retryable(resp.StatusCode) {
+			return nil, apiErr
+		}
+
+		delay := b.retry.wait(resp, attempt)
+		b.log.Printf("bookstack: %s %s failed, retrying in %s: %v", method, req.URL.Path, delay, apiErr)
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
 }
 
 type Single interface {
-	User | Book | BookDetailed | Chapter | ChapterDetailed | Page | PageDetailed | Shelf | ShelfDetailed | RecycledBook | RecycledPage | RecycledChapter | Attachment | AttachmentDetailed
+	User | Book | BookDetailed | Chapter | ChapterDetailed | Page | PageDetailed | Shelf | ShelfDetailed | RecycledBook | RecycledPage | RecycledChapter | Attachment | AttachmentDetailed | Role | RoleDetailed | ImageDetailed | ContentPermissions | SystemInfo | Import | ImportDetailed | ImportedContent
 }
 
 type Group interface {
-	[]User | []Book | []Chapter | []Page | []Shelf | []RecycleBinItem | []Attachment | []Search
+	[]User | []Book | []Chapter | []Page | []Shelf | []RecycleBinItem | []Attachment | []Search | []Role | []AuditLogEntry | []Image | []Import
 }
 
 func ParseSingle[s Single](data []byte) (s, error) {
@@ -0,0 +1,196 @@
+package bookstacktest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// AddAttachment stores an attachment, filling in its id and timestamps when
+// they are empty, and returns the stored copy. Content holds the base64
+// encoded file, or the link for external attachments.
+func (s *Server) AddAttachment(attachment bookstack.AttachmentDetailed) bookstack.AttachmentDetailed {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.addAttachment(attachment)
+}
+
+// Attachment returns the stored attachment with the given id.
+func (s *Server) Attachment(id int) (bookstack.AttachmentDetailed, bool) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attachment, ok := s.attachments[id]
+	if !ok {
+		return bookstack.AttachmentDetailed{}, false
+	}
+
+	return *attachment, true
+}
+
+func (s *Server) addAttachment(attachment bookstack.AttachmentDetailed) *bookstack.AttachmentDetailed {
+
+	if attachment.ID == 0 {
+		attachment.ID = s.next("attachment")
+	} else if attachment.ID > s.seq["attachment"] {
+		s.seq["attachment"] = attachment.ID
+	}
+
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = s.now()
+	}
+
+	if attachment.UpdatedAt.IsZero() {
+		attachment.UpdatedAt = attachment.CreatedAt
+	}
+
+	if attachment.CreatedBy.ID == 0 {
+		attachment.CreatedBy = s.actor()
+	}
+
+	if attachment.UpdatedBy.ID == 0 {
+		attachment.UpdatedBy = bookstack.UpdatedBy(s.actor())
+	}
+
+	s.setAttachmentLinks(&attachment)
+
+	s.attachments[attachment.ID] = &attachment
+
+	return &attachment
+}
+
+func (s *Server) setAttachmentLinks(a *bookstack.AttachmentDetailed) {
+
+	url := fmt.Sprintf("%s/attachments/%d", s.URL, a.ID)
+
+	a.Links = bookstack.Links{
+		HTML:     fmt.Sprintf(`<a target="_blank" href="%s">%s</a>`, url, a.Name),
+		Markdown: fmt.Sprintf("[%s](%s)", a.Name, url),
+	}
+}
+
+func attachmentSummary(d *bookstack.AttachmentDetailed) bookstack.Attachment {
+	return bookstack.Attachment{
+		ID:         d.ID,
+		Name:       d.Name,
+		Extension:  d.Extension,
+		UploadedTo: d.UploadedTo,
+		External:   d.External,
+		Order:      d.Order,
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+		CreatedBy:  d.CreatedBy.ID,
+		UpdatedBy:  d.UpdatedBy.ID,
+	}
+}
+
+// setAttachmentContent applies the file or link in the payload.
+func setAttachmentContent(a *bookstack.AttachmentDetailed, p payload) {
+
+	if f, ok := p.files["file"]; ok {
+		a.External = false
+		a.Extension = strings.TrimPrefix(filepath.Ext(f.name), ".")
+		a.Content = base64.StdEncoding.EncodeToString(f.data)
+		return
+	}
+
+	if link := p.str("link"); link != "" {
+		a.External = true
+		a.Extension = ""
+		a.Content = link
+	}
+}
+
+func (s *Server) handleAttachments(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) == 1 {
+
+		switch r.method {
+		case http.MethodGet:
+
+			list := []bookstack.Attachment{}
+			for _, a := range s.attachments {
+				list = append(list, attachmentSummary(a))
+			}
+
+			writeList(w, r, list)
+
+		case http.MethodPost:
+
+			if _, ok := r.payload.files["file"]; !ok && r.payload.str("link") == "" {
+				writeValidation(w, map[string][]string{
+					"file": {"The file field is required when link is not present."},
+					"link": {"The link field is required when file is not present."},
+				})
+				return
+			}
+
+			if !r.payload.required(w, "name", "uploaded_to") {
+				return
+			}
+
+			if _, ok := s.pages[r.payload.int("uploaded_to")]; !ok {
+				notFound(w, "Page")
+				return
+			}
+
+			attachment := bookstack.AttachmentDetailed{
+				Name:       r.payload.str("name"),
+				UploadedTo: r.payload.int("uploaded_to"),
+			}
+
+			setAttachmentContent(&attachment, r.payload)
+
+			writeJSON(w, http.StatusOK, attachmentSummary(s.addAttachment(attachment)))
+
+		default:
+			methodNotAllowed(w)
+		}
+
+		return
+	}
+
+	id, _ := r.id(1)
+
+	attachment, ok := s.attachments[id]
+	if !ok || len(r.segs) != 2 {
+		notFound(w, "Attachment")
+		return
+	}
+
+	switch r.method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, attachment)
+
+	case http.MethodPut:
+
+		if r.payload.has("name") {
+			attachment.Name = r.payload.str("name")
+		}
+
+		if r.payload.has("uploaded_to") {
+			attachment.UploadedTo = r.payload.int("uploaded_to")
+		}
+
+		setAttachmentContent(attachment, r.payload)
+		s.setAttachmentLinks(attachment)
+
+		attachment.UpdatedAt = s.now()
+
+		writeJSON(w, http.StatusOK, attachmentSummary(attachment))
+
+	case http.MethodDelete:
+		delete(s.attachments, attachment.ID)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
@@ -0,0 +1,78 @@
+package bookstacktest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// AddAuditLogEntry stores an audit log entry, filling in its id, user and
+// timestamp when they are empty, and returns the stored copy. Entries are
+// also recorded automatically as content, users and roles change.
+func (s *Server) AddAuditLogEntry(entry bookstack.AuditLogEntry) bookstack.AuditLogEntry {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.addAuditLogEntry(entry)
+}
+
+func (s *Server) addAuditLogEntry(entry bookstack.AuditLogEntry) *bookstack.AuditLogEntry {
+
+	if entry.ID == 0 {
+		entry.ID = s.next("audit")
+	} else if entry.ID > s.seq["audit"] {
+		s.seq["audit"] = entry.ID
+	}
+
+	if entry.UserID == 0 {
+		entry.UserID = s.actor().ID
+	}
+
+	if u, ok := s.users[entry.UserID]; ok && entry.User.ID == 0 {
+		entry.User = bookstack.AuditLogUser{ID: u.ID, Name: u.Name, Slug: u.Slug}
+	}
+
+	if entry.IP == "" {
+		entry.IP = "127.0.0.1"
+	}
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = s.now()
+	}
+
+	s.auditLog[entry.ID] = &entry
+
+	return &entry
+}
+
+// audit records event against the item of the given type and id.
+func (s *Server) audit(event bookstack.AuditEvent, kind bookstack.ContentType, id int, name string) {
+	s.addAuditLogEntry(bookstack.AuditLogEntry{
+		Event:        event,
+		Detail:       fmt.Sprintf("(%d) %s", id, name),
+		LoggableType: kind,
+		LoggableID:   id,
+	})
+}
+
+func (s *Server) handleAuditLog(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) != 1 {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	if r.method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	list := []bookstack.AuditLogEntry{}
+	for _, e := range s.auditLog {
+		list = append(list, *e)
+	}
+
+	writeList(w, r, list)
+}
@@ -0,0 +1,356 @@
+package bookstacktest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// AddBook stores a book, filling in its id, slug and timestamps when they
+// are empty, and returns the stored copy.
+func (s *Server) AddBook(book bookstack.BookDetailed) bookstack.BookDetailed {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.addBook(book)
+}
+
+// Book returns the stored book with the given id.
+func (s *Server) Book(id int) (bookstack.BookDetailed, bool) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	book, ok := s.books[id]
+	if !ok {
+		return bookstack.BookDetailed{}, false
+	}
+
+	return *book, true
+}
+
+func (s *Server) addBook(book bookstack.BookDetailed) *bookstack.BookDetailed {
+
+	if book.ID == 0 {
+		book.ID = s.next("book")
+	} else if book.ID > s.seq["book"] {
+		s.seq["book"] = book.ID
+	}
+
+	if book.Slug == "" {
+		book.Slug = slugify(book.Name)
+	}
+
+	if book.CreatedAt.IsZero() {
+		book.CreatedAt = s.now()
+	}
+
+	if book.UpdatedAt.IsZero() {
+		book.UpdatedAt = book.CreatedAt
+	}
+
+	if book.CreatedBy.ID == 0 {
+		book.CreatedBy = s.actor()
+	}
+
+	if book.UpdatedBy.ID == 0 {
+		book.UpdatedBy = bookstack.UpdatedBy(s.actor())
+	}
+
+	if book.OwnedBy.ID == 0 {
+		book.OwnedBy = bookstack.OwnedBy(s.actor())
+	}
+
+	s.books[book.ID] = &book
+
+	return &book
+}
+
+func bookSummary(d *bookstack.BookDetailed) bookstack.Book {
+	return bookstack.Book{
+		ID:          d.ID,
+		Name:        d.Name,
+		Slug:        d.Slug,
+		Description: d.Description,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+		CreatedBy:   d.CreatedBy.ID,
+		UpdatedBy:   d.UpdatedBy.ID,
+		OwnedBy:     d.OwnedBy.ID,
+	}
+}
+
+func (s *Server) cover(kind string, id int, file upload) bookstack.Cover {
+	return bookstack.Cover{
+		ID:         s.next("image"),
+		Name:       file.name,
+		URL:        fmt.Sprintf("%s/uploads/images/cover_%s/%s", s.URL, kind, file.name),
+		CreatedAt:  s.now(),
+		UpdatedAt:  s.now(),
+		CreatedBy:  s.actor().ID,
+		UpdatedBy:  s.actor().ID,
+		Path:       fmt.Sprintf("/uploads/images/cover_%s/%s", kind, file.name),
+		Type:       "cover_" + kind,
+		UploadedTo: id,
+	}
+}
+
+func (s *Server) handleBooks(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) == 1 {
+
+		switch r.method {
+		case http.MethodGet:
+
+			list := []bookstack.Book{}
+			for _, b := range s.books {
+				list = append(list, bookSummary(b))
+			}
+
+			writeList(w, r, list)
+
+		case http.MethodPost:
+
+			if !r.payload.required(w, "name") {
+				return
+			}
+
+			book := s.addBook(bookstack.BookDetailed{
+				Name:        r.payload.str("name"),
+				Description: r.payload.str("description"),
+				Tags:        r.payload.tags(),
+			})
+
+			if img, ok := r.payload.files["image"]; ok {
+				book.Cover = s.cover("book", book.ID, img)
+			}
+
+			s.audit(bookstack.AuditBookCreate, bookstack.ContentBook, book.ID, book.Name)
+
+			writeJSON(w, http.StatusOK, bookSummary(book))
+
+		default:
+			methodNotAllowed(w)
+		}
+
+		return
+	}
+
+	id, _ := r.id(1)
+
+	book, ok := s.books[id]
+	if !ok {
+		notFound(w, "Book")
+		return
+	}
+
+	if len(r.segs) == 4 && r.segs[2] == "export" && r.method == http.MethodGet {
+
+		if r.segs[3] == "zip" {
+			s.exportZip(w, bookstack.ContentBook, book.ID)
+			return
+		}
+
+		s.exportBook(w, book, r.segs[3])
+		return
+	}
+
+	if len(r.segs) != 2 {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	switch r.method {
+	case http.MethodGet:
+
+		detailed := *book
+
+		if s.version != "" {
+			detailed.Contents = s.contents(book.ID)
+		}
+
+		writeJSON(w, http.StatusOK, detailed)
+
+	case http.MethodPut:
+
+		if r.payload.has("name") {
+			book.Name = r.payload.str("name")
+			book.Slug = slugify(book.Name)
+		}
+
+		if r.payload.has("description") {
+			book.Description = r.payload.str("description")
+		}
+
+		if r.payload.has("tags") {
+			book.Tags = r.payload.tags()
+		}
+
+		if img, ok := r.payload.files["image"]; ok {
+			book.Cover = s.cover("book", book.ID, img)
+		}
+
+		book.UpdatedAt = s.now()
+
+		s.audit(bookstack.AuditBookUpdate, bookstack.ContentBook, book.ID, book.Name)
+
+		writeJSON(w, http.StatusOK, bookSummary(book))
+
+	case http.MethodDelete:
+		s.audit(bookstack.AuditBookDelete, bookstack.ContentBook, book.ID, book.Name)
+		s.deleteBook(book)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// bookContents returns the chapters and direct pages of a book, ordered by
+// priority.
+func (s *Server) bookContents(bookID int) ([]*bookstack.ChapterDetailed, []*bookstack.PageDetailed) {
+
+	chapters := []*bookstack.ChapterDetailed{}
+	for _, c := range s.chapters {
+		if c.BookID == bookID {
+			chapters = append(chapters, c)
+		}
+	}
+
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].Priority < chapters[j].Priority || chapters[i].Priority == chapters[j].Priority && chapters[i].ID < chapters[j].ID
+	})
+
+	pages := []*bookstack.PageDetailed{}
+	for _, p := range s.pages {
+		if p.BookID == bookID && p.ChapterID == 0 {
+			pages = append(pages, p)
+		}
+	}
+
+	sortPages(pages)
+
+	return chapters, pages
+}
+
+// contents returns the contents of a book as BookStack includes them in the
+// book, with chapters and direct pages sorted together by priority.
+func (s *Server) contents(bookID int) []bookstack.BookContent {
+
+	chapters, pages := s.bookContents(bookID)
+
+	contents := []bookstack.BookContent{}
+
+	for _, c := range chapters {
+
+		item := bookstack.BookContent{
+			ID:        c.ID,
+			Name:      c.Name,
+			Slug:      c.Slug,
+			BookID:    c.BookID,
+			Priority:  c.Priority,
+			Type:      bookstack.ContentChapter,
+			URL:       fmt.Sprintf("%s/books/%s/chapter/%s", s.URL, s.books[bookID].Slug, c.Slug),
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
+			Pages:     []bookstack.BookContent{},
+		}
+
+		for _, p := range s.chapterPages(c.ID) {
+			item.Pages = append(item.Pages, s.pageContent(p))
+		}
+
+		contents = append(contents, item)
+	}
+
+	for _, p := range pages {
+		contents = append(contents, s.pageContent(p))
+	}
+
+	sort.SliceStable(contents, func(i, j int) bool {
+		return contents[i].Priority < contents[j].Priority
+	})
+
+	return contents
+}
+
+func (s *Server) pageContent(p *bookstack.PageDetailed) bookstack.BookContent {
+	return bookstack.BookContent{
+		ID:        p.ID,
+		Name:      p.Name,
+		Slug:      p.Slug,
+		BookID:    p.BookID,
+		ChapterID: p.ChapterID,
+		Priority:  p.Priority,
+		Type:      bookstack.ContentPage,
+		URL:       fmt.Sprintf("%s/books/%s/page/%s", s.URL, s.books[p.BookID].Slug, p.Slug),
+		Draft:     p.Draft,
+		Template:  p.Template,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+func (s *Server) exportBook(w http.ResponseWriter, book *bookstack.BookDetailed, format string) {
+
+	chapters, pages := s.bookContents(book.ID)
+
+	for _, c := range chapters {
+		pages = append(pages, s.chapterPages(c.ID)...)
+	}
+
+	writeExport(w, book.Slug, book.Name, pages, format)
+}
+
+func writeExport(w http.ResponseWriter, slug, name string, pages []*bookstack.PageDetailed, format string) {
+
+	var (
+		b           strings.Builder
+		contentType string
+		ext         string
+	)
+
+	switch format {
+	case "html":
+		contentType, ext = "text/html; charset=utf-8", "html"
+		fmt.Fprintf(&b, "<!doctype html><html><head><title>%s</title></head><body><h1>%s</h1>", name, name)
+		for _, p := range pages {
+			fmt.Fprintf(&b, "<h2>%s</h2>%s", p.Name, p.HTML)
+		}
+		b.WriteString("</body></html>")
+	case "markdown":
+		contentType, ext = "text/markdown; charset=utf-8", "md"
+		fmt.Fprintf(&b, "# %s\n\n", name)
+		for _, p := range pages {
+			fmt.Fprintf(&b, "## %s\n\n%s\n\n", p.Name, pageMarkdown(p))
+		}
+	case "plaintext":
+		contentType, ext = "text/plain; charset=utf-8", "txt"
+		fmt.Fprintf(&b, "%s\n\n", name)
+		for _, p := range pages {
+			fmt.Fprintf(&b, "%s\n\n%s\n\n", p.Name, plain(p.HTML))
+		}
+	case "pdf":
+		contentType, ext = "application/pdf", "pdf"
+		fmt.Fprintf(&b, "%%PDF-1.4\n%% %s\n", base64.StdEncoding.EncodeToString([]byte(name)))
+		for _, p := range pages {
+			fmt.Fprintf(&b, "%% %s\n", base64.StdEncoding.EncodeToString([]byte(plain(p.HTML))))
+		}
+		b.WriteString("%%EOF\n")
+	default:
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, path.Base(slug+"."+ext)))
+	w.Header().Set("Content-Length", fmt.Sprint(b.Len()))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(b.String()))
+}
@@ -0,0 +1,272 @@
+package bookstacktest
+
+import (
+	"net/http"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// AddChapter stores a chapter, filling in its id, slug and timestamps when
+// they are empty, and returns the stored copy.
+func (s *Server) AddChapter(chapter bookstack.ChapterDetailed) bookstack.ChapterDetailed {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.chapterDetailed(s.addChapter(chapter))
+}
+
+// Chapter returns the stored chapter with the given id.
+func (s *Server) Chapter(id int) (bookstack.ChapterDetailed, bool) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chapter, ok := s.chapters[id]
+	if !ok {
+		return bookstack.ChapterDetailed{}, false
+	}
+
+	return s.chapterDetailed(chapter), true
+}
+
+func (s *Server) addChapter(chapter bookstack.ChapterDetailed) *bookstack.ChapterDetailed {
+
+	if chapter.ID == 0 {
+		chapter.ID = s.next("chapter")
+	} else if chapter.ID > s.seq["chapter"] {
+		s.seq["chapter"] = chapter.ID
+	}
+
+	if chapter.Slug == "" {
+		chapter.Slug = slugify(chapter.Name)
+	}
+
+	if chapter.CreatedAt.IsZero() {
+		chapter.CreatedAt = s.now()
+	}
+
+	if chapter.UpdatedAt.IsZero() {
+		chapter.UpdatedAt = chapter.CreatedAt
+	}
+
+	if chapter.CreatedBy.ID == 0 {
+		chapter.CreatedBy = s.actor()
+	}
+
+	if chapter.UpdatedBy.ID == 0 {
+		chapter.UpdatedBy = bookstack.UpdatedBy(s.actor())
+	}
+
+	if chapter.OwnedBy.ID == 0 {
+		chapter.OwnedBy = bookstack.OwnedBy(s.actor())
+	}
+
+	if chapter.Priority == 0 {
+		chapter.Priority = s.nextPriority(chapter.BookID)
+	}
+
+	chapter.Pages = nil
+
+	s.chapters[chapter.ID] = &chapter
+
+	return &chapter
+}
+
+// nextPriority returns the priority for a new item at the end of a book.
+func (s *Server) nextPriority(bookID int) int {
+
+	max := 0
+
+	for _, c := range s.chapters {
+		if c.BookID == bookID && c.Priority > max {
+			max = c.Priority
+		}
+	}
+
+	for _, p := range s.pages {
+		if p.BookID == bookID && p.Priority > max {
+			max = p.Priority
+		}
+	}
+
+	return max + 1
+}
+
+func (s *Server) chapterPages(chapterID int) []*bookstack.PageDetailed {
+
+	pages := []*bookstack.PageDetailed{}
+
+	for _, p := range s.pages {
+		if p.ChapterID == chapterID && chapterID != 0 {
+			pages = append(pages, p)
+		}
+	}
+
+	sortPages(pages)
+
+	return pages
+}
+
+func (s *Server) chapterDetailed(c *bookstack.ChapterDetailed) bookstack.ChapterDetailed {
+
+	result := *c
+	result.Pages = []bookstack.ChapterPage{}
+
+	for _, p := range s.chapterPages(c.ID) {
+		result.Pages = append(result.Pages, bookstack.ChapterPage{
+			ID:            p.ID,
+			BookID:        p.BookID,
+			ChapterID:     p.ChapterID,
+			Name:          p.Name,
+			Slug:          p.Slug,
+			Priority:      p.Priority,
+			CreatedAt:     p.CreatedAt,
+			UpdatedAt:     p.UpdatedAt,
+			CreatedBy:     p.CreatedBy.ID,
+			UpdatedBy:     p.UpdatedBy.ID,
+			Draft:         p.Draft,
+			RevisionCount: p.RevisionCount,
+			Template:      p.Template,
+		})
+	}
+
+	return result
+}
+
+func chapterSummary(d *bookstack.ChapterDetailed) bookstack.Chapter {
+	return bookstack.Chapter{
+		ID:          d.ID,
+		BookID:      d.BookID,
+		Name:        d.Name,
+		Slug:        d.Slug,
+		Description: d.Description,
+		Priority:    d.Priority,
+		CreatedAt:   d.CreatedAt.Format(timeFormat),
+		UpdatedAt:   d.UpdatedAt,
+		CreatedBy:   d.CreatedBy.ID,
+		UpdatedBy:   d.UpdatedBy.ID,
+		OwnedBy:     d.OwnedBy.ID,
+	}
+}
+
+func (s *Server) handleChapters(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) == 1 {
+
+		switch r.method {
+		case http.MethodGet:
+
+			list := []bookstack.Chapter{}
+			for _, c := range s.chapters {
+				list = append(list, chapterSummary(c))
+			}
+
+			writeList(w, r, list)
+
+		case http.MethodPost:
+
+			if !r.payload.required(w, "book_id", "name") {
+				return
+			}
+
+			if _, ok := s.books[r.payload.int("book_id")]; !ok {
+				notFound(w, "Book")
+				return
+			}
+
+			chapter := s.addChapter(bookstack.ChapterDetailed{
+				BookID:      r.payload.int("book_id"),
+				Name:        r.payload.str("name"),
+				Description: r.payload.str("description"),
+				Priority:    r.payload.int("priority"),
+				Tags:        r.payload.tags(),
+			})
+
+			s.audit(bookstack.AuditChapterCreate, bookstack.ContentChapter, chapter.ID, chapter.Name)
+
+			writeJSON(w, http.StatusOK, chapterSummary(chapter))
+
+		default:
+			methodNotAllowed(w)
+		}
+
+		return
+	}
+
+	id, _ := r.id(1)
+
+	chapter, ok := s.chapters[id]
+	if !ok {
+		notFound(w, "Chapter")
+		return
+	}
+
+	if len(r.segs) == 4 && r.segs[2] == "export" && r.method == http.MethodGet {
+
+		if r.segs[3] == "zip" {
+			s.exportZip(w, bookstack.ContentChapter, chapter.ID)
+			return
+		}
+
+		writeExport(w, chapter.Slug, chapter.Name, s.chapterPages(chapter.ID), r.segs[3])
+		return
+	}
+
+	if len(r.segs) != 2 {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	switch r.method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.chapterDetailed(chapter))
+
+	case http.MethodPut:
+
+		if r.payload.has("name") {
+			chapter.Name = r.payload.str("name")
+			chapter.Slug = slugify(chapter.Name)
+		}
+
+		if r.payload.has("description") {
+			chapter.Description = r.payload.str("description")
+		}
+
+		if r.payload.has("priority") {
+			chapter.Priority = r.payload.int("priority")
+		}
+
+		if r.payload.has("tags") {
+			chapter.Tags = r.payload.tags()
+		}
+
+		if book := r.payload.int("book_id"); book != 0 && book != chapter.BookID {
+
+			if _, ok := s.books[book]; !ok {
+				notFound(w, "Book")
+				return
+			}
+
+			chapter.BookID = book
+
+			for _, p := range s.chapterPages(chapter.ID) {
+				p.BookID = book
+			}
+		}
+
+		chapter.UpdatedAt = s.now()
+
+		s.audit(bookstack.AuditChapterUpdate, bookstack.ContentChapter, chapter.ID, chapter.Name)
+
+		writeJSON(w, http.StatusOK, chapterSummary(chapter))
+
+	case http.MethodDelete:
+		s.audit(bookstack.AuditChapterDelete, bookstack.ContentChapter, chapter.ID, chapter.Name)
+		s.deleteChapter(chapter)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
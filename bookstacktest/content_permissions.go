@@ -0,0 +1,167 @@
+package bookstacktest
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// contentKey identifies an item that can hold permissions.
+type contentKey struct {
+	kind bookstack.ContentType
+	id   int
+}
+
+// contentPermissions are the stored permissions of an item. Items without
+// an entry inherit from their parent.
+type contentPermissions struct {
+	roles    []bookstack.RolePermission
+	fallback bookstack.FallbackPermissions
+}
+
+// owner returns the owner of the item of the given type and id.
+func (s *Server) owner(kind bookstack.ContentType, id int) (*bookstack.OwnedBy, string, bool) {
+
+	switch kind {
+	case bookstack.ContentBook:
+		if b, ok := s.books[id]; ok {
+			return &b.OwnedBy, b.Name, true
+		}
+	case bookstack.ContentChapter:
+		if c, ok := s.chapters[id]; ok {
+			return &c.OwnedBy, c.Name, true
+		}
+	case bookstack.ContentPage:
+		if p, ok := s.pages[id]; ok {
+			return &p.OwnedBy, p.Name, true
+		}
+	case bookstack.ContentShelf:
+		if sh, ok := s.shelves[id]; ok {
+			return &sh.OwnedBy, sh.Name, true
+		}
+	}
+
+	return nil, "", false
+}
+
+func (s *Server) contentPermissions(key contentKey, owner *bookstack.OwnedBy) bookstack.ContentPermissions {
+
+	result := bookstack.ContentPermissions{
+		Owner:               bookstack.ContentOwner{ID: owner.ID, Name: owner.Name},
+		RolePermissions:     []bookstack.RolePermission{},
+		FallbackPermissions: bookstack.FallbackPermissions{Inheriting: true},
+	}
+
+	if u, ok := s.users[owner.ID]; ok {
+		result.Owner.Slug = u.Slug
+	}
+
+	perms, ok := s.permissions[key]
+	if !ok {
+		return result
+	}
+
+	result.FallbackPermissions = perms.fallback
+
+	for _, p := range perms.roles {
+
+		if role, ok := s.roles[p.RoleID]; ok {
+			p.Role = &bookstack.Role{ID: role.ID, DisplayName: role.DisplayName}
+		}
+
+		result.RolePermissions = append(result.RolePermissions, p)
+	}
+
+	return result
+}
+
+func (s *Server) handleContentPermissions(w http.ResponseWriter, r *request) {
+
+	id, ok := r.id(2)
+	if !ok || len(r.segs) != 3 {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	key := contentKey{kind: bookstack.ContentType(r.segs[1]), id: id}
+
+	owner, name, ok := s.owner(key.kind, key.id)
+	if !ok {
+		notFound(w, "Content")
+		return
+	}
+
+	switch r.method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.contentPermissions(key, owner))
+
+	case http.MethodPut:
+
+		user := s.users[owner.ID]
+
+		if r.payload.has("owner_id") {
+
+			if user, ok = s.users[r.payload.int("owner_id")]; !ok {
+				writeValidation(w, map[string][]string{"owner_id": {"The selected owner id is invalid."}})
+				return
+			}
+
+		}
+
+		perms := contentPermissions{fallback: bookstack.FallbackPermissions{Inheriting: true}}
+		if stored, ok := s.permissions[key]; ok {
+			perms = *stored
+		}
+
+		if r.payload.has("role_permissions") {
+
+			roles := []bookstack.RolePermission{}
+			if err := r.payload.decode("role_permissions", &roles); err != nil {
+				writeValidation(w, map[string][]string{"role_permissions": {err.Error()}})
+				return
+			}
+
+			for _, p := range roles {
+				if _, ok := s.roles[p.RoleID]; !ok {
+					writeValidation(w, map[string][]string{"role_permissions": {"The selected role id is invalid."}})
+					return
+				}
+			}
+
+			sort.Slice(roles, func(i, j int) bool {
+				return roles[i].RoleID < roles[j].RoleID
+			})
+
+			perms.roles = roles
+		}
+
+		if r.payload.has("fallback_permissions") {
+
+			fallback := bookstack.FallbackPermissions{}
+			if err := r.payload.decode("fallback_permissions", &fallback); err != nil {
+				writeValidation(w, map[string][]string{"fallback_permissions": {err.Error()}})
+				return
+			}
+
+			if fallback.Inheriting {
+				fallback = bookstack.FallbackPermissions{Inheriting: true}
+			}
+
+			perms.fallback = fallback
+		}
+
+		if user != nil {
+			*owner = bookstack.OwnedBy{ID: user.ID, Name: user.Name}
+		}
+
+		s.permissions[key] = &perms
+
+		s.audit(bookstack.AuditPermissionsUpdate, key.kind, key.id, name)
+
+		writeJSON(w, http.StatusOK, s.contentPermissions(key, owner))
+
+	default:
+		methodNotAllowed(w)
+	}
+}
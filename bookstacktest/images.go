@@ -0,0 +1,235 @@
+package bookstacktest
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// AddImage stores a gallery image, filling in its id, urls, thumbnails and
+// timestamps when they are empty, and returns the stored copy.
+func (s *Server) AddImage(image bookstack.ImageDetailed) bookstack.ImageDetailed {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.addImage(image)
+}
+
+// Image returns the stored image with the given id, along with the contents
+// of its last uploaded file.
+func (s *Server) Image(id int) (bookstack.ImageDetailed, []byte, bool) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	image, ok := s.images[id]
+	if !ok {
+		return bookstack.ImageDetailed{}, nil, false
+	}
+
+	return *image, s.imageData[id], true
+}
+
+func (s *Server) addImage(image bookstack.ImageDetailed) *bookstack.ImageDetailed {
+
+	if image.ID == 0 {
+		image.ID = s.next("image")
+	} else if image.ID > s.seq["image"] {
+		s.seq["image"] = image.ID
+	}
+
+	if image.Type == "" {
+		image.Type = bookstack.ImageGallery
+	}
+
+	if image.CreatedAt.IsZero() {
+		image.CreatedAt = s.now()
+	}
+
+	if image.UpdatedAt.IsZero() {
+		image.UpdatedAt = image.CreatedAt
+	}
+
+	if image.CreatedBy.ID == 0 {
+		image.CreatedBy = s.actor()
+	}
+
+	if image.UpdatedBy.ID == 0 {
+		image.UpdatedBy = bookstack.UpdatedBy(s.actor())
+	}
+
+	if image.Path == "" {
+		image.Path = fmt.Sprintf("/uploads/images/%s/%s/%s", image.Type, image.CreatedAt.Format("2006-01"), image.Name)
+	}
+
+	s.setImageURLs(&image)
+
+	s.images[image.ID] = &image
+
+	return &image
+}
+
+func (s *Server) setImageURLs(i *bookstack.ImageDetailed) {
+
+	dir, name := path.Dir(i.Path)+"/", path.Base(i.Path)
+
+	i.URL = s.URL + i.Path
+	i.Thumbs = bookstack.ImageThumbs{
+		Gallery: fmt.Sprintf("%s%sthumbs-150-150/%s", s.URL, dir, name),
+		Display: fmt.Sprintf("%s%sscaled-1680-/%s", s.URL, dir, name),
+	}
+
+	if i.Type == bookstack.ImageDrawio {
+		html := fmt.Sprintf(`<div drawio-diagram="%d"><img src="%s"></div>`, i.ID, i.URL)
+		i.Content = bookstack.ImageContent{HTML: html, Markdown: html}
+		return
+	}
+
+	i.Content = bookstack.ImageContent{
+		HTML:     fmt.Sprintf(`<a href="%s" target="_blank"><img src="%s" alt="%s"></a>`, i.URL, i.Thumbs.Display, i.Name),
+		Markdown: fmt.Sprintf("![%s](%s)", i.Name, i.Thumbs.Display),
+	}
+}
+
+func imageSummary(d *bookstack.ImageDetailed) bookstack.Image {
+	return bookstack.Image{
+		ID:         d.ID,
+		Name:       d.Name,
+		URL:        d.URL,
+		Path:       d.Path,
+		Type:       d.Type,
+		UploadedTo: d.UploadedTo,
+		CreatedBy:  d.CreatedBy.ID,
+		UpdatedBy:  d.UpdatedBy.ID,
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+	}
+}
+
+func (s *Server) handleImages(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) == 1 {
+
+		switch r.method {
+		case http.MethodGet:
+
+			list := []bookstack.Image{}
+			for _, i := range s.images {
+				list = append(list, imageSummary(i))
+			}
+
+			writeList(w, r, list)
+
+		case http.MethodPost:
+
+			file, ok := r.payload.files["image"]
+			if !ok {
+				writeValidation(w, map[string][]string{"image": {"The image field is required."}})
+				return
+			}
+
+			if !r.payload.required(w, "type", "uploaded_to") {
+				return
+			}
+
+			kind := bookstack.ImageType(r.payload.str("type"))
+			if kind != bookstack.ImageGallery && kind != bookstack.ImageDrawio {
+				writeValidation(w, map[string][]string{"type": {"The selected type is invalid."}})
+				return
+			}
+
+			if _, ok := s.pages[r.payload.int("uploaded_to")]; !ok {
+				notFound(w, "Page")
+				return
+			}
+
+			name := r.payload.str("name")
+			if name == "" {
+				name = file.name
+			}
+
+			image := s.addImage(bookstack.ImageDetailed{
+				Name:       name,
+				Type:       kind,
+				UploadedTo: r.payload.int("uploaded_to"),
+				Path:       fmt.Sprintf("/uploads/images/%s/%s/%s", kind, s.now().Format("2006-01"), file.name),
+			})
+
+			s.imageData[image.ID] = file.data
+
+			writeJSON(w, http.StatusOK, image)
+
+		default:
+			methodNotAllowed(w)
+		}
+
+		return
+	}
+
+	id, _ := r.id(1)
+
+	image, ok := s.images[id]
+	if !ok || len(r.segs) != 2 {
+		notFound(w, "Image")
+		return
+	}
+
+	switch r.method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, image)
+
+	case http.MethodPut:
+
+		if r.payload.has("name") {
+			image.Name = r.payload.str("name")
+			s.setImageURLs(image)
+		}
+
+		// A replaced file keeps the path, and so the urls, of the original.
+		if file, ok := r.payload.files["image"]; ok {
+			s.imageData[image.ID] = file.data
+		}
+
+		image.UpdatedAt = s.now()
+		image.UpdatedBy = bookstack.UpdatedBy(s.actor())
+
+		writeJSON(w, http.StatusOK, image)
+
+	case http.MethodDelete:
+		delete(s.images, image.ID)
+		delete(s.imageData, image.ID)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// serveImageFile writes the last uploaded file of the image stored at the
+// given path.
+func (s *Server) serveImageFile(w http.ResponseWriter, file string) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, image := range s.images {
+
+		if image.Path != file {
+			continue
+		}
+
+		if t := mime.TypeByExtension(path.Ext(file)); t != "" {
+			w.Header().Set("Content-Type", t)
+		}
+
+		w.Write(s.imageData[id])
+
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "Image not found")
+}
@@ -0,0 +1,337 @@
+package bookstacktest
+
+import (
+	"html"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+const timeFormat = "2006-01-02T15:04:05.000000Z"
+
+// AddPage stores a page, filling in its id, slug and timestamps when they are
+// empty, and returns the stored copy.
+func (s *Server) AddPage(page bookstack.PageDetailed) bookstack.PageDetailed {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.addPage(page)
+}
+
+// Page returns the stored page with the given id.
+func (s *Server) Page(id int) (bookstack.PageDetailed, bool) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page, ok := s.pages[id]
+	if !ok {
+		return bookstack.PageDetailed{}, false
+	}
+
+	return *page, true
+}
+
+func (s *Server) addPage(page bookstack.PageDetailed) *bookstack.PageDetailed {
+
+	if page.ID == 0 {
+		page.ID = s.next("page")
+	} else if page.ID > s.seq["page"] {
+		s.seq["page"] = page.ID
+	}
+
+	if page.Slug == "" {
+		page.Slug = slugify(page.Name)
+	}
+
+	if page.CreatedAt.IsZero() {
+		page.CreatedAt = s.now()
+	}
+
+	if page.UpdatedAt.IsZero() {
+		page.UpdatedAt = page.CreatedAt
+	}
+
+	if page.CreatedBy.ID == 0 {
+		page.CreatedBy = s.actor()
+	}
+
+	if page.UpdatedBy.ID == 0 {
+		page.UpdatedBy = bookstack.UpdatedBy(s.actor())
+	}
+
+	if page.OwnedBy.ID == 0 {
+		page.OwnedBy = bookstack.OwnedBy(s.actor())
+	}
+
+	if page.ChapterID != 0 && page.BookID == 0 {
+		if c, ok := s.chapters[page.ChapterID]; ok {
+			page.BookID = c.BookID
+		}
+	}
+
+	if page.Priority == 0 {
+		page.Priority = s.nextPriority(page.BookID)
+	}
+
+	if page.RevisionCount == 0 {
+		page.RevisionCount = 1
+	}
+
+	if page.HTML == "" && page.Markdown != "" {
+		page.HTML = renderMarkdown(page.Markdown)
+	}
+
+	s.pages[page.ID] = &page
+
+	return &page
+}
+
+func sortPages(pages []*bookstack.PageDetailed) {
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Priority < pages[j].Priority || pages[i].Priority == pages[j].Priority && pages[i].ID < pages[j].ID
+	})
+}
+
+func pageSummary(d *bookstack.PageDetailed) bookstack.Page {
+	return bookstack.Page{
+		ID:            d.ID,
+		BookID:        d.BookID,
+		ChapterID:     d.ChapterID,
+		Name:          d.Name,
+		Slug:          d.Slug,
+		Priority:      d.Priority,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+		CreatedBy:     d.CreatedBy.ID,
+		UpdatedBy:     d.UpdatedBy.ID,
+		Draft:         d.Draft,
+		RevisionCount: d.RevisionCount,
+		Template:      d.Template,
+	}
+}
+
+var markdownHeading = regexp.MustCompile(`(?m)^(#{1,6})\s+(.*)$`)
+
+// renderMarkdown is a minimal stand-in for BookStack's markdown renderer.
+func renderMarkdown(md string) string {
+
+	var b strings.Builder
+
+	for _, block := range strings.Split(strings.TrimSpace(md), "\n\n") {
+
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+
+		if m := markdownHeading.FindStringSubmatch(block); m != nil && !strings.Contains(block, "\n") {
+			n := len(m[1])
+			b.WriteString("<h" + string(rune('0'+n)) + ">" + html.EscapeString(m[2]) + "</h" + string(rune('0'+n)) + ">")
+			continue
+		}
+
+		b.WriteString("<p>" + html.EscapeString(block) + "</p>")
+	}
+
+	return b.String()
+}
+
+var tags = regexp.MustCompile(`<[^>]*>`)
+
+// plain strips markup from HTML.
+func plain(s string) string {
+	return strings.TrimSpace(html.UnescapeString(tags.ReplaceAllString(s, " ")))
+}
+
+func pageMarkdown(p *bookstack.PageDetailed) string {
+
+	if p.Markdown != "" {
+		return p.Markdown
+	}
+
+	return plain(p.HTML)
+}
+
+func (s *Server) handlePages(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) == 1 {
+
+		switch r.method {
+		case http.MethodGet:
+
+			list := []bookstack.Page{}
+			for _, p := range s.pages {
+				list = append(list, pageSummary(p))
+			}
+
+			writeList(w, r, list)
+
+		case http.MethodPost:
+
+			if !r.payload.required(w, "name") {
+				return
+			}
+
+			book, chapter := r.payload.int("book_id"), r.payload.int("chapter_id")
+
+			if book == 0 && chapter == 0 {
+				writeValidation(w, map[string][]string{
+					"book_id":    {"The book id field is required when chapter id is not present."},
+					"chapter_id": {"The chapter id field is required when book id is not present."},
+				})
+				return
+			}
+
+			if chapter != 0 {
+
+				c, ok := s.chapters[chapter]
+				if !ok {
+					notFound(w, "Chapter")
+					return
+				}
+
+				book = c.BookID
+			}
+
+			if _, ok := s.books[book]; !ok {
+				notFound(w, "Book")
+				return
+			}
+
+			if r.payload.str("html") == "" && r.payload.str("markdown") == "" {
+				writeValidation(w, map[string][]string{
+					"html":     {"The html field is required when markdown is not present."},
+					"markdown": {"The markdown field is required when html is not present."},
+				})
+				return
+			}
+
+			page := s.addPage(bookstack.PageDetailed{
+				BookID:    book,
+				ChapterID: chapter,
+				Name:      r.payload.str("name"),
+				HTML:      r.payload.str("html"),
+				Markdown:  r.payload.str("markdown"),
+				Priority:  r.payload.int("priority"),
+				Tags:      r.payload.tags(),
+			})
+
+			s.audit(bookstack.AuditPageCreate, bookstack.ContentPage, page.ID, page.Name)
+
+			writeJSON(w, http.StatusOK, page)
+
+		default:
+			methodNotAllowed(w)
+		}
+
+		return
+	}
+
+	id, _ := r.id(1)
+
+	page, ok := s.pages[id]
+	if !ok {
+		notFound(w, "Page")
+		return
+	}
+
+	if len(r.segs) == 4 && r.segs[2] == "export" && r.method == http.MethodGet {
+
+		if r.segs[3] == "zip" {
+			s.exportZip(w, bookstack.ContentPage, page.ID)
+			return
+		}
+
+		s.exportPage(w, page, r.segs[3])
+		return
+	}
+
+	if len(r.segs) != 2 {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	switch r.method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, page)
+
+	case http.MethodPut:
+
+		if r.payload.has("name") {
+			page.Name = r.payload.str("name")
+			page.Slug = slugify(page.Name)
+		}
+
+		if r.payload.has("markdown") {
+			page.Markdown = r.payload.str("markdown")
+			page.HTML = renderMarkdown(page.Markdown)
+		}
+
+		if r.payload.has("html") {
+			page.HTML = r.payload.str("html")
+			page.Markdown = ""
+		}
+
+		if r.payload.has("priority") {
+			page.Priority = r.payload.int("priority")
+		}
+
+		if r.payload.has("tags") {
+			page.Tags = r.payload.tags()
+		}
+
+		if chapter := r.payload.int("chapter_id"); chapter != 0 {
+
+			c, ok := s.chapters[chapter]
+			if !ok {
+				notFound(w, "Chapter")
+				return
+			}
+
+			page.ChapterID, page.BookID = c.ID, c.BookID
+
+		} else if book := r.payload.int("book_id"); book != 0 {
+
+			if _, ok := s.books[book]; !ok {
+				notFound(w, "Book")
+				return
+			}
+
+			page.ChapterID, page.BookID = 0, book
+		}
+
+		page.RevisionCount++
+		page.UpdatedAt = s.now()
+		page.UpdatedBy = bookstack.UpdatedBy(s.actor())
+
+		s.audit(bookstack.AuditPageUpdate, bookstack.ContentPage, page.ID, page.Name)
+
+		writeJSON(w, http.StatusOK, page)
+
+	case http.MethodDelete:
+		s.audit(bookstack.AuditPageDelete, bookstack.ContentPage, page.ID, page.Name)
+		s.deletePage(page)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (s *Server) exportPage(w http.ResponseWriter, page *bookstack.PageDetailed, format string) {
+
+	if format != "markdown" {
+		writeExport(w, page.Slug, page.Name, []*bookstack.PageDetailed{page}, format)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+page.Slug+`.md"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("# " + page.Name + "\n\n" + pageMarkdown(page) + "\n"))
+}
@@ -0,0 +1,232 @@
+package bookstacktest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// deletion is an entry in the recycle bin.
+type deletion struct {
+	item    bookstack.RecycleBinItem
+	count   int
+	restore func()
+}
+
+func (s *Server) recycle(kind bookstack.ContentType, id int, deletable interface{}, count int, restore func()) {
+
+	raw, _ := json.Marshal(deletable)
+
+	d := &deletion{
+		item: bookstack.RecycleBinItem{
+			ID:            s.next("deletion"),
+			DeletedBy:     s.actor().ID,
+			CreatedAt:     s.now(),
+			UpdatedAt:     s.now(),
+			DeletableType: kind,
+			DeletableID:   id,
+			Deletable:     raw,
+		},
+		count:   count,
+		restore: restore,
+	}
+
+	s.deletions[d.item.ID] = d
+}
+
+func (s *Server) parent(bookID, chapterID int) bookstack.Parent {
+
+	if c, ok := s.chapters[chapterID]; ok {
+		return bookstack.Parent{
+			ID:          c.ID,
+			Name:        c.Name,
+			Slug:        c.Slug,
+			Description: c.Description,
+			CreatedAt:   c.CreatedAt,
+			UpdatedAt:   c.UpdatedAt,
+			CreatedBy:   c.CreatedBy.ID,
+			UpdatedBy:   c.UpdatedBy.ID,
+			OwnedBy:     c.OwnedBy.ID,
+			Type:        string(bookstack.ContentChapter),
+		}
+	}
+
+	if b, ok := s.books[bookID]; ok {
+		return bookstack.Parent{
+			ID:          b.ID,
+			Name:        b.Name,
+			Slug:        b.Slug,
+			Description: b.Description,
+			CreatedAt:   b.CreatedAt,
+			UpdatedAt:   b.UpdatedAt,
+			CreatedBy:   b.CreatedBy.ID,
+			UpdatedBy:   b.UpdatedBy.ID,
+			OwnedBy:     b.OwnedBy.ID,
+			Type:        string(bookstack.ContentBook),
+		}
+	}
+
+	return bookstack.Parent{}
+}
+
+func (s *Server) bookSlug(bookID int) string {
+
+	if b, ok := s.books[bookID]; ok {
+		return b.Slug
+	}
+
+	return ""
+}
+
+func (s *Server) deletePage(page *bookstack.PageDetailed) {
+
+	deletable := bookstack.RecycledPage{
+		ID:            page.ID,
+		BookID:        page.BookID,
+		ChapterID:     page.ChapterID,
+		Name:          page.Name,
+		Slug:          page.Slug,
+		Priority:      page.Priority,
+		CreatedAt:     page.CreatedAt,
+		UpdatedAt:     page.UpdatedAt,
+		CreatedBy:     page.CreatedBy.ID,
+		UpdatedBy:     page.UpdatedBy.ID,
+		Draft:         page.Draft,
+		RevisionCount: page.RevisionCount,
+		Template:      page.Template,
+		OwnedBy:       page.OwnedBy.ID,
+		BookSlug:      s.bookSlug(page.BookID),
+		Parent:        s.parent(page.BookID, page.ChapterID),
+	}
+
+	delete(s.pages, page.ID)
+
+	s.recycle(bookstack.ContentPage, page.ID, deletable, 1, func() {
+		s.pages[page.ID] = page
+	})
+}
+
+func (s *Server) deleteChapter(chapter *bookstack.ChapterDetailed) {
+
+	pages := s.chapterPages(chapter.ID)
+
+	deletable := bookstack.RecycledChapter{
+		Chapter:    chapterSummary(chapter),
+		BookSlug:   s.bookSlug(chapter.BookID),
+		PagesCount: len(pages),
+		Parent:     s.parent(chapter.BookID, 0),
+	}
+
+	delete(s.chapters, chapter.ID)
+
+	for _, p := range pages {
+		delete(s.pages, p.ID)
+	}
+
+	s.recycle(bookstack.ContentChapter, chapter.ID, deletable, 1+len(pages), func() {
+
+		s.chapters[chapter.ID] = chapter
+
+		for _, p := range pages {
+			s.pages[p.ID] = p
+		}
+	})
+}
+
+func (s *Server) deleteBook(book *bookstack.BookDetailed) {
+
+	chapters := []*bookstack.ChapterDetailed{}
+	for _, c := range s.chapters {
+		if c.BookID == book.ID {
+			chapters = append(chapters, c)
+		}
+	}
+
+	pages := []*bookstack.PageDetailed{}
+	for _, p := range s.pages {
+		if p.BookID == book.ID {
+			pages = append(pages, p)
+		}
+	}
+
+	deletable := bookstack.RecycledBook{
+		Book:          bookSummary(book),
+		PagesCount:    len(pages),
+		ChaptersCount: len(chapters),
+	}
+
+	delete(s.books, book.ID)
+
+	for _, c := range chapters {
+		delete(s.chapters, c.ID)
+	}
+
+	for _, p := range pages {
+		delete(s.pages, p.ID)
+	}
+
+	s.recycle(bookstack.ContentBook, book.ID, deletable, 1+len(chapters)+len(pages), func() {
+
+		s.books[book.ID] = book
+
+		for _, c := range chapters {
+			s.chapters[c.ID] = c
+		}
+
+		for _, p := range pages {
+			s.pages[p.ID] = p
+		}
+	})
+}
+
+func (s *Server) deleteShelf(shelf *bookstack.ShelfDetailed) {
+
+	delete(s.shelves, shelf.ID)
+
+	s.recycle(bookstack.ContentShelf, shelf.ID, shelfSummary(shelf), 1, func() {
+		s.shelves[shelf.ID] = shelf
+	})
+}
+
+func (s *Server) handleRecycleBin(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) == 1 {
+
+		if r.method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+
+		list := []bookstack.RecycleBinItem{}
+		for _, d := range s.deletions {
+			list = append(list, d.item)
+		}
+
+		writeList(w, r, list)
+
+		return
+	}
+
+	id, _ := r.id(1)
+
+	d, ok := s.deletions[id]
+	if !ok || len(r.segs) != 2 {
+		notFound(w, "Deletion")
+		return
+	}
+
+	switch r.method {
+	case http.MethodPut:
+		d.restore()
+		delete(s.deletions, id)
+		writeJSON(w, http.StatusOK, map[string]int{"restore_count": d.count})
+
+	case http.MethodDelete:
+		delete(s.deletions, id)
+		writeJSON(w, http.StatusOK, map[string]int{"delete_count": d.count})
+
+	default:
+		methodNotAllowed(w)
+	}
+}
@@ -0,0 +1,467 @@
+package bookstacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// upload is a file sent in a multipart request.
+type upload struct {
+	name string
+	data []byte
+}
+
+// payload is a decoded JSON or multipart request body.
+type payload struct {
+	values map[string]interface{}
+	files  map[string]upload
+}
+
+// formKey matches the keys PHP reads as arrays: name[], name[N] and
+// name[N][field].
+var formKey = regexp.MustCompile(`^(\w+)\[(\d*)\](?:\[(\w+)\])?$`)
+
+func readPayload(r *http.Request) (payload, error) {
+
+	p := payload{
+		values: map[string]interface{}{},
+		files:  map[string]upload{},
+	}
+
+	if r.Body == nil {
+		return p, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "multipart/form-data":
+
+		// PHP only parses multipart bodies on POST.
+		if r.Method != http.MethodPost {
+			return p, fmt.Errorf("multipart bodies are not read on %s", r.Method)
+		}
+
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return p, err
+		}
+
+		// Arrays are read as PHP reads them. Repeating a key without [] only
+		// keeps its last value.
+		arrays := map[string]map[int]interface{}{}
+
+		for key, values := range r.MultipartForm.Value {
+
+			m := formKey.FindStringSubmatch(key)
+			if m == nil {
+				p.values[key] = values[len(values)-1]
+				continue
+			}
+
+			if arrays[m[1]] == nil {
+				arrays[m[1]] = map[int]interface{}{}
+			}
+
+			items := arrays[m[1]]
+
+			switch {
+			case m[2] == "":
+				for _, v := range values {
+					items[len(items)] = v
+				}
+
+			case m[3] == "":
+				n, _ := strconv.Atoi(m[2])
+				items[n] = values[len(values)-1]
+
+			default:
+				n, _ := strconv.Atoi(m[2])
+				fields, ok := items[n].(map[string]interface{})
+				if !ok {
+					fields = map[string]interface{}{}
+					items[n] = fields
+				}
+				fields[m[3]] = values[len(values)-1]
+			}
+		}
+
+		for key, items := range arrays {
+
+			order := []int{}
+			for n := range items {
+				order = append(order, n)
+			}
+			sort.Ints(order)
+
+			list := []interface{}{}
+			for _, n := range order {
+				list = append(list, items[n])
+			}
+			p.values[key] = list
+		}
+
+		for key, headers := range r.MultipartForm.File {
+
+			f, err := headers[0].Open()
+			if err != nil {
+				return p, err
+			}
+
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return p, err
+			}
+
+			p.files[key] = upload{name: headers[0].Filename, data: data}
+		}
+
+	case "application/json":
+
+		if err := json.NewDecoder(r.Body).Decode(&p.values); err != nil && err != io.EOF {
+			return p, err
+		}
+
+	}
+
+	return p, nil
+}
+
+func (p payload) has(key string) bool {
+	_, ok := p.values[key]
+	return ok
+}
+
+func (p payload) str(key string) string {
+
+	switch v := p.values[key].(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (p payload) int(key string) int {
+
+	switch v := p.values[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	default:
+		return 0
+	}
+}
+
+func (p payload) bool(key string) bool {
+
+	switch v := p.values[key].(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v == "1" || v == "true"
+	default:
+		return false
+	}
+}
+
+func (p payload) ints(key string) []int {
+
+	list, _ := p.values[key].([]interface{})
+
+	result := []int{}
+
+	for _, v := range list {
+		switch v := v.(type) {
+		case float64:
+			result = append(result, int(v))
+		case string:
+			if n, err := strconv.Atoi(v); err == nil {
+				result = append(result, n)
+			}
+		}
+	}
+
+	return result
+}
+
+func (p payload) tags() []bookstack.Tag {
+
+	list, _ := p.values["tags"].([]interface{})
+
+	result := []bookstack.Tag{}
+
+	for i, v := range list {
+
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := m["name"].(string)
+		value, _ := m["value"].(string)
+
+		if name == "" {
+			continue
+		}
+
+		result = append(result, bookstack.Tag{Name: name, Value: value, Order: i})
+	}
+
+	return result
+}
+
+// decode unmarshals the value of key into v.
+func (p payload) decode(key string, v interface{}) error {
+
+	raw, err := json.Marshal(p.values[key])
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// required writes a validation error for any of the keys missing from the
+// payload and reports whether all of them were present.
+func (p payload) required(w http.ResponseWriter, keys ...string) bool {
+
+	missing := map[string][]string{}
+
+	for _, key := range keys {
+		if p.str(key) == "" {
+			if _, ok := p.files[key]; !ok {
+				missing[key] = []string{fmt.Sprintf("The %s field is required.", strings.ReplaceAll(key, "_", " "))}
+			}
+		}
+	}
+
+	if len(missing) == 0 {
+		return true
+	}
+
+	writeValidation(w, missing)
+
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    status,
+			"message": msg,
+		},
+	})
+}
+
+func writeValidation(w http.ResponseWriter, fields map[string][]string) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":       http.StatusUnprocessableEntity,
+			"message":    "The given data was invalid.",
+			"validation": fields,
+		},
+	})
+}
+
+var filterKey = regexp.MustCompile(`^filter\[([a-z_]+)(?::(eq|ne|gt|lt|gte|lte|like))?\]$`)
+
+// writeList applies the standard listing parameters (filter, sort, offset and
+// count) to items and writes them in BookStack's list format.
+func writeList[T any](w http.ResponseWriter, r *request, items []T) {
+
+	raw, err := json.Marshal(items)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rows := []map[string]interface{}{}
+
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for key, values := range r.query {
+
+		m := filterKey.FindStringSubmatch(key)
+		if m == nil {
+			continue
+		}
+
+		kept := []map[string]interface{}{}
+
+		for _, row := range rows {
+			if match(row[m[1]], m[2], values[0]) {
+				kept = append(kept, row)
+			}
+		}
+
+		rows = kept
+	}
+
+	field, desc := "id", false
+
+	if s := first(r.query, "sort"); s != "" {
+		field = strings.TrimLeft(s, "+- ")
+		desc = strings.HasPrefix(s, "-")
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return compare(rows[j][field], rows[i][field]) < 0
+		}
+		return compare(rows[i][field], rows[j][field]) < 0
+	})
+
+	total := len(rows)
+
+	offset, _ := strconv.Atoi(first(r.query, "offset"))
+	if offset > len(rows) {
+		offset = len(rows)
+	}
+
+	count, err := strconv.Atoi(first(r.query, "count"))
+	if err != nil || count <= 0 {
+		count = 100
+	}
+
+	if count > 500 {
+		count = 500
+	}
+
+	end := offset + count
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  rows[offset:end],
+		"total": total,
+	})
+}
+
+func first(q map[string][]string, key string) string {
+
+	if v := q[key]; len(v) > 0 {
+		return v[0]
+	}
+
+	return ""
+}
+
+func match(v interface{}, op, want string) bool {
+
+	switch op {
+	case "", "eq":
+		return compare(v, want) == 0
+	case "ne":
+		return compare(v, want) != 0
+	case "gt":
+		return compare(v, want) > 0
+	case "gte":
+		return compare(v, want) >= 0
+	case "lt":
+		return compare(v, want) < 0
+	case "lte":
+		return compare(v, want) <= 0
+	case "like":
+		pattern := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(want), "%", ".*") + "$"
+		ok, _ := regexp.MatchString(pattern, text(v))
+		return ok
+	}
+
+	return false
+}
+
+// compare orders two values numerically when both are numbers,
+// chronologically when both are times, and as strings otherwise.
+func compare(a, b interface{}) int {
+
+	x, y := text(a), text(b)
+
+	if tx, ok := parseTime(x); ok {
+		if ty, ok := parseTime(y); ok {
+			switch {
+			case tx.Before(ty):
+				return -1
+			case tx.After(ty):
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	if fx, err := strconv.ParseFloat(x, 64); err == nil {
+		if fy, err := strconv.ParseFloat(y, 64); err == nil {
+			switch {
+			case fx < fy:
+				return -1
+			case fx > fy:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(strings.ToLower(x), strings.ToLower(y))
+}
+
+// timeLayouts are the layouts accepted for dates in filters and in stored
+// items.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+func parseTime(v string) (time.Time, bool) {
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func text(v interface{}) string {
+
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,204 @@
+package bookstacktest
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// AddRole stores a role, filling in its id and timestamps when they are
+// empty, and returns the stored copy. Users are assigned to roles through
+// AddUser, so the Users of role are ignored.
+func (s *Server) AddRole(role bookstack.RoleDetailed) bookstack.RoleDetailed {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.roleDetailed(s.addRole(role))
+}
+
+func (s *Server) addRole(role bookstack.RoleDetailed) *bookstack.RoleDetailed {
+
+	if role.ID == 0 {
+		role.ID = s.next("role")
+	} else if role.ID > s.seq["role"] {
+		s.seq["role"] = role.ID
+	}
+
+	if role.CreatedAt.IsZero() {
+		role.CreatedAt = s.now()
+	}
+
+	if role.UpdatedAt.IsZero() {
+		role.UpdatedAt = role.CreatedAt
+	}
+
+	role.Users = nil
+
+	s.roles[role.ID] = &role
+
+	return &role
+}
+
+func (s *Server) roleUsers(id int) []bookstack.RoleUser {
+
+	users := []bookstack.RoleUser{}
+
+	for _, u := range s.users {
+		for _, r := range u.Roles {
+			if r.ID == id {
+				users = append(users, bookstack.RoleUser{ID: u.ID, Name: u.Name, Slug: u.Slug})
+			}
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	return users
+}
+
+func (s *Server) roleDetailed(r *bookstack.RoleDetailed) bookstack.RoleDetailed {
+
+	result := *r
+	result.Users = s.roleUsers(r.ID)
+
+	if result.Permissions == nil {
+		result.Permissions = []string{}
+	}
+
+	return result
+}
+
+func (s *Server) roleSummary(r *bookstack.RoleDetailed) bookstack.Role {
+	return bookstack.Role{
+		ID:               r.ID,
+		DisplayName:      r.DisplayName,
+		Description:      r.Description,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
+		SystemName:       r.SystemName,
+		ExternalAuthID:   r.ExternalAuthID,
+		MFAEnforced:      r.MFAEnforced,
+		UsersCount:       len(s.roleUsers(r.ID)),
+		PermissionsCount: len(r.Permissions),
+	}
+}
+
+func permissions(p payload) []string {
+
+	list, _ := p.values["permissions"].([]interface{})
+
+	result := []string{}
+
+	for _, v := range list {
+		if name, ok := v.(string); ok {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+func (s *Server) handleRoles(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) == 1 {
+
+		switch r.method {
+		case http.MethodGet:
+
+			list := []bookstack.Role{}
+			for _, role := range s.roles {
+				list = append(list, s.roleSummary(role))
+			}
+
+			writeList(w, r, list)
+
+		case http.MethodPost:
+
+			if !r.payload.required(w, "display_name") {
+				return
+			}
+
+			role := s.addRole(bookstack.RoleDetailed{
+				DisplayName:    r.payload.str("display_name"),
+				Description:    r.payload.str("description"),
+				ExternalAuthID: r.payload.str("external_auth_id"),
+				MFAEnforced:    r.payload.bool("mfa_enforced"),
+				Permissions:    permissions(r.payload),
+			})
+
+			writeJSON(w, http.StatusOK, s.roleDetailed(role))
+
+		default:
+			methodNotAllowed(w)
+		}
+
+		return
+	}
+
+	id, _ := r.id(1)
+
+	role, ok := s.roles[id]
+	if !ok || len(r.segs) != 2 {
+		notFound(w, "Role")
+		return
+	}
+
+	switch r.method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.roleDetailed(role))
+
+	case http.MethodPut:
+
+		if r.payload.has("display_name") {
+			role.DisplayName = r.payload.str("display_name")
+		}
+
+		if r.payload.has("description") {
+			role.Description = r.payload.str("description")
+		}
+
+		if r.payload.has("external_auth_id") {
+			role.ExternalAuthID = r.payload.str("external_auth_id")
+		}
+
+		if r.payload.has("mfa_enforced") {
+			role.MFAEnforced = r.payload.bool("mfa_enforced")
+		}
+
+		if r.payload.has("permissions") {
+			role.Permissions = permissions(r.payload)
+		}
+
+		role.UpdatedAt = s.now()
+
+		writeJSON(w, http.StatusOK, s.roleDetailed(role))
+
+	case http.MethodDelete:
+
+		if role.SystemName != "" {
+			writeError(w, http.StatusForbidden, "This role is a system role and cannot be deleted")
+			return
+		}
+
+		delete(s.roles, role.ID)
+
+		for _, u := range s.users {
+			kept := u.Roles[:0]
+			for _, ur := range u.Roles {
+				if ur.ID != role.ID {
+					kept = append(kept, ur)
+				}
+			}
+			u.Roles = kept
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
@@ -0,0 +1,371 @@
+package bookstacktest
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/searchquery"
+)
+
+// entity is the searchable view of any content item.
+type entity struct {
+	result bookstack.Search
+	body   string
+}
+
+func (s *Server) entities() []entity {
+
+	list := []entity{}
+
+	for _, b := range s.books {
+		list = append(list, entity{
+			result: bookstack.Search{
+				ID:        b.ID,
+				Slug:      b.Slug,
+				Name:      b.Name,
+				CreatedAt: b.CreatedAt,
+				UpdatedAt: b.UpdatedAt,
+				Type:      bookstack.ContentBook,
+				URL:       fmt.Sprintf("%s/books/%s", s.URL, b.Slug),
+				Tags:      b.Tags,
+			},
+			body: b.Description,
+		})
+	}
+
+	for _, c := range s.chapters {
+		list = append(list, entity{
+			result: bookstack.Search{
+				ID:        c.ID,
+				BookID:    c.BookID,
+				Slug:      c.Slug,
+				Name:      c.Name,
+				CreatedAt: c.CreatedAt,
+				UpdatedAt: c.UpdatedAt,
+				Type:      bookstack.ContentChapter,
+				URL:       fmt.Sprintf("%s/books/%s/chapter/%s", s.URL, s.bookSlug(c.BookID), c.Slug),
+				Tags:      c.Tags,
+			},
+			body: c.Description,
+		})
+	}
+
+	for _, p := range s.pages {
+		list = append(list, entity{
+			result: bookstack.Search{
+				ID:        p.ID,
+				BookID:    p.BookID,
+				ChapterID: p.ChapterID,
+				Slug:      p.Slug,
+				Name:      p.Name,
+				CreatedAt: p.CreatedAt,
+				UpdatedAt: p.UpdatedAt,
+				Type:      bookstack.ContentPage,
+				URL:       fmt.Sprintf("%s/books/%s/page/%s", s.URL, s.bookSlug(p.BookID), p.Slug),
+				Tags:      p.Tags,
+				Draft:     p.Draft,
+				Template:  p.Template,
+			},
+			body: plain(p.HTML),
+		})
+	}
+
+	for _, sh := range s.shelves {
+		list = append(list, entity{
+			result: bookstack.Search{
+				ID:        sh.ID,
+				Slug:      sh.Slug,
+				Name:      sh.Name,
+				CreatedAt: sh.CreatedAt,
+				UpdatedAt: sh.UpdatedAt,
+				Type:      bookstack.ContentShelf,
+				URL:       fmt.Sprintf("%s/shelves/%s", s.URL, sh.Slug),
+				Tags:      sh.Tags,
+			},
+			body: sh.Description,
+		})
+	}
+
+	return list
+}
+
+// searchMatcher is a parsed search query.
+type searchMatcher []func(e entity) bool
+
+// tagOperators maps the operators of tag searches to those of list filters.
+var tagOperators = map[searchquery.Operator]string{
+	searchquery.Equals:         "eq",
+	searchquery.NotEquals:      "ne",
+	searchquery.Less:           "lt",
+	searchquery.Greater:        "gt",
+	searchquery.LessOrEqual:    "lte",
+	searchquery.GreaterOrEqual: "gte",
+	searchquery.Like:           "like",
+}
+
+// parseSearch returns the matcher of a query, and the terms and phrases it
+// looks for.
+func parseSearch(query string) (searchMatcher, []string, error) {
+
+	q, err := searchquery.Parse(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := searchMatcher{}
+	terms := []string{}
+
+	for _, c := range q {
+
+		var (
+			fn      func(entity) bool
+			negated bool
+		)
+
+		switch c := c.(type) {
+		case searchquery.Term:
+			fn, negated = textMatcher(c.Value), c.Negated
+
+			if !negated {
+				terms = append(terms, c.Value)
+			}
+
+		case searchquery.Exact:
+			fn, negated = textMatcher(c.Value), c.Negated
+
+			if !negated {
+				terms = append(terms, c.Value)
+			}
+
+		case searchquery.Tag:
+			fn, negated = tagMatcher(c), c.Negated
+
+		case searchquery.Filter:
+			fn, negated = filterMatcher(string(c.Name), c.Value), c.Negated
+		}
+
+		// Filters the fake cannot evaluate, such as those based on the
+		// current user, are left out.
+		if fn == nil {
+			continue
+		}
+
+		if negated {
+			inner := fn
+			fn = func(e entity) bool { return !inner(e) }
+		}
+
+		m = append(m, fn)
+	}
+
+	return m, terms, nil
+}
+
+// highlight escapes text, wrapping the terms found in it in <strong> like
+// BookStack does in previews.
+func highlight(text string, terms []string) string {
+
+	lower := strings.ToLower(text)
+	marked := make([]bool, len(text))
+
+	for _, term := range terms {
+
+		term = strings.ToLower(term)
+
+		if term == "" || len(lower) != len(text) {
+			continue
+		}
+
+		for from := 0; ; {
+
+			n := strings.Index(lower[from:], term)
+			if n < 0 {
+				break
+			}
+
+			for i := from + n; i < from+n+len(term); i++ {
+				marked[i] = true
+			}
+
+			from += n + len(term)
+		}
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(text); {
+
+		end := i
+		for end < len(text) && marked[end] == marked[i] {
+			end++
+		}
+
+		if marked[i] {
+			b.WriteString("<strong>" + html.EscapeString(text[i:end]) + "</strong>")
+		} else {
+			b.WriteString(html.EscapeString(text[i:end]))
+		}
+
+		i = end
+	}
+
+	return b.String()
+}
+
+func textMatcher(value string) func(entity) bool {
+
+	term := strings.ToLower(value)
+
+	return func(e entity) bool {
+		return strings.Contains(strings.ToLower(e.result.Name), term) || strings.Contains(strings.ToLower(e.body), term)
+	}
+}
+
+func tagMatcher(t searchquery.Tag) func(entity) bool {
+
+	return func(e entity) bool {
+
+		for _, tag := range e.result.Tags {
+
+			if t.Name != "" && !strings.EqualFold(tag.Name, t.Name) {
+				continue
+			}
+
+			if t.Operator == "" || match(tag.Value, tagOperators[t.Operator], t.Value) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func filterMatcher(name, value string) func(entity) bool {
+
+	date := func(v string) time.Time {
+		t, _ := time.Parse(bookstack.SearchDateFormat, v)
+		return t
+	}
+
+	switch name {
+	case "type":
+		return func(e entity) bool {
+			for _, t := range strings.Split(value, "|") {
+				if bookstack.ContentType(t) == e.result.Type {
+					return true
+				}
+			}
+			return false
+		}
+	case "in_name":
+		return func(e entity) bool {
+			return strings.Contains(strings.ToLower(e.result.Name), strings.ToLower(value))
+		}
+	case "in_body":
+		return func(e entity) bool {
+			return strings.Contains(strings.ToLower(e.body), strings.ToLower(value))
+		}
+	case "updated_after":
+		return func(e entity) bool { return !e.result.UpdatedAt.Before(date(value)) }
+	case "updated_before":
+		return func(e entity) bool { return e.result.UpdatedAt.Before(date(value)) }
+	case "created_after":
+		return func(e entity) bool { return !e.result.CreatedAt.Before(date(value)) }
+	case "created_before":
+		return func(e entity) bool { return e.result.CreatedAt.Before(date(value)) }
+	case "is_template":
+		return func(e entity) bool { return e.result.Template }
+	case "in_book":
+		return func(e entity) bool { return strconv.Itoa(e.result.BookID) == value }
+	case "in_chapter":
+		return func(e entity) bool { return strconv.Itoa(e.result.ChapterID) == value }
+	default:
+		return nil
+	}
+}
+
+func (m searchMatcher) match(e entity) bool {
+
+	for _, fn := range m {
+		if !fn(e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *request) {
+
+	if r.method != http.MethodGet || len(r.segs) != 1 {
+		methodNotAllowed(w)
+		return
+	}
+
+	query := first(r.query, "query")
+
+	if strings.TrimSpace(query) == "" {
+		writeValidation(w, map[string][]string{"query": {"The query field is required."}})
+		return
+	}
+
+	matcher, terms, err := parseSearch(query)
+	if err != nil {
+		writeValidation(w, map[string][]string{"query": {err.Error()}})
+		return
+	}
+
+	results := []bookstack.Search{}
+
+	for _, e := range s.entities() {
+
+		if !matcher.match(e) {
+			continue
+		}
+
+		e.result.PreviewHTML = bookstack.PreviewHTML{
+			Name:    highlight(e.result.Name, terms),
+			Content: highlight(e.body, terms),
+		}
+
+		results = append(results, e.result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Type != results[j].Type {
+			return results[i].Type < results[j].Type
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	page, _ := strconv.Atoi(first(r.query, "page"))
+	if page < 1 {
+		page = 1
+	}
+
+	count, _ := strconv.Atoi(first(r.query, "count"))
+	if count < 1 || count > 100 {
+		count = 100
+	}
+
+	start := (page - 1) * count
+	if start > len(results) {
+		start = len(results)
+	}
+
+	end := start + count
+	if end > len(results) {
+		end = len(results)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  results[start:end],
+		"total": len(results),
+	})
+}
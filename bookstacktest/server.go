@@ -0,0 +1,239 @@
+// Package bookstacktest provides an in-memory BookStack API server for
+// exercising the bookstack client without a real instance.
+package bookstacktest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+const (
+	// TokenID is the API token id accepted by the server.
+	TokenID = "bookstacktest"
+	// TokenSecret is the API token secret accepted by the server.
+	TokenSecret = "bookstacktest"
+)
+
+// Server is a fake BookStack instance backed by in-memory state.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	version     string
+	seq         map[string]int
+	books       map[int]*bookstack.BookDetailed
+	chapters    map[int]*bookstack.ChapterDetailed
+	pages       map[int]*bookstack.PageDetailed
+	shelves     map[int]*bookstack.ShelfDetailed
+	shelfBooks  map[int][]int
+	attachments map[int]*bookstack.AttachmentDetailed
+	users       map[int]*bookstack.User
+	roles       map[int]*bookstack.RoleDetailed
+	deletions   map[int]*deletion
+	images      map[int]*bookstack.ImageDetailed
+	imageData   map[int][]byte
+	auditLog    map[int]*bookstack.AuditLogEntry
+	permissions map[contentKey]*contentPermissions
+	imports     map[int]*storedImport
+	removed     map[string]bool
+}
+
+// NewServer starts a fake BookStack server seeded with the users and roles
+// of a fresh install. The caller should call Close when finished.
+func NewServer() *Server {
+
+	s := &Server{
+		version:     DefaultVersion,
+		seq:         map[string]int{},
+		books:       map[int]*bookstack.BookDetailed{},
+		chapters:    map[int]*bookstack.ChapterDetailed{},
+		pages:       map[int]*bookstack.PageDetailed{},
+		shelves:     map[int]*bookstack.ShelfDetailed{},
+		shelfBooks:  map[int][]int{},
+		attachments: map[int]*bookstack.AttachmentDetailed{},
+		users:       map[int]*bookstack.User{},
+		roles:       map[int]*bookstack.RoleDetailed{},
+		deletions:   map[int]*deletion{},
+		images:      map[int]*bookstack.ImageDetailed{},
+		imageData:   map[int][]byte{},
+		auditLog:    map[int]*bookstack.AuditLogEntry{},
+		permissions: map[contentKey]*contentPermissions{},
+		imports:     map[int]*storedImport{},
+		removed:     map[string]bool{},
+	}
+
+	s.addRole(bookstack.RoleDetailed{DisplayName: "Admin", SystemName: "admin", Description: "Administrator of the whole application", Permissions: []string{"settings-manage", "users-manage", "user-roles-manage"}})
+	s.addRole(bookstack.RoleDetailed{DisplayName: "Editor", Description: "User can edit Books, Chapters & Pages"})
+	s.addRole(bookstack.RoleDetailed{DisplayName: "Viewer", Description: "User can view books & their content behind authentication"})
+	s.addRole(bookstack.RoleDetailed{DisplayName: "Public", SystemName: "public", Description: "The role given to public visitors if allowed"})
+
+	s.setUserRoles(s.addUser(bookstack.User{Name: "Admin", Email: "admin@admin.com"}), []int{1})
+	s.setUserRoles(s.addUser(bookstack.User{Name: "Guest", Email: "guest@example.com"}), []int{4})
+
+	s.Server = httptest.NewServer(s)
+
+	for _, u := range s.users {
+		s.setUserURLs(u)
+	}
+
+	return s
+}
+
+// Client returns a client pointed at the server, authenticated with the
+// server's token. Any given options are applied after the defaults.
+func (s *Server) Client(opts ...bookstack.Option) *bookstack.Bookstack {
+
+	defaults := []bookstack.Option{
+		bookstack.SetURL(s.URL),
+		bookstack.SetToken(TokenID, TokenSecret),
+	}
+
+	return bookstack.New(append(defaults, opts...)...)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if r.Header.Get("Authorization") == "" {
+		writeError(w, http.StatusUnauthorized, "No authorization token found on the request")
+		return
+	}
+
+	if r.Header.Get("Authorization") != fmt.Sprintf("Token %s:%s", TokenID, TokenSecret) {
+		writeError(w, http.StatusUnauthorized, "No matching API token was found for the provided authorization token")
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/uploads/images/") && r.Method == http.MethodGet {
+		s.serveImageFile(w, r.URL.Path)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/api/") {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	p, err := readPayload(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	method := r.Method
+	if override := p.str("_method"); method == http.MethodPost && override != "" {
+		method = strings.ToUpper(override)
+	}
+
+	req := &request{
+		method:  method,
+		segs:    strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/"), "/"), "/"),
+		query:   r.URL.Query(),
+		payload: p,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.removed[req.segs[0]] {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	var h func(http.ResponseWriter, *request)
+
+	switch req.segs[0] {
+	case "books":
+		h = s.handleBooks
+	case "chapters":
+		h = s.handleChapters
+	case "pages":
+		h = s.handlePages
+	case "shelves":
+		h = s.handleShelves
+	case "attachments":
+		h = s.handleAttachments
+	case "users":
+		h = s.handleUsers
+	case "roles":
+		h = s.handleRoles
+	case "search":
+		h = s.handleSearch
+	case "recycle-bin":
+		h = s.handleRecycleBin
+	case "image-gallery":
+		h = s.handleImages
+	case "content-permissions":
+		h = s.handleContentPermissions
+	case "imports":
+		h = s.handleImports
+	case "system":
+		h = s.handleSystem
+	case "audit-log":
+		h = s.handleAuditLog
+	default:
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	h(w, req)
+}
+
+func (s *Server) next(kind string) int {
+	s.seq[kind]++
+	return s.seq[kind]
+}
+
+func (s *Server) now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (s *Server) actor() bookstack.CreatedBy {
+	u := s.users[1]
+	return bookstack.CreatedBy{ID: u.ID, Name: u.Name}
+}
+
+// request is a routed API request.
+type request struct {
+	method  string
+	segs    []string
+	query   map[string][]string
+	payload payload
+}
+
+// id returns the numeric path segment at i.
+func (r *request) id(i int) (int, bool) {
+
+	if len(r.segs) <= i {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(r.segs[i])
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugify(name string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func notFound(w http.ResponseWriter, kind string) {
+	writeError(w, http.StatusNotFound, fmt.Sprintf("%s not found", kind))
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+}
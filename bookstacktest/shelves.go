@@ -0,0 +1,197 @@
+package bookstacktest
+
+import (
+	"net/http"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// AddShelf stores a shelf, filling in its id, slug and timestamps when they
+// are empty, and returns the stored copy. The ids of the shelf's Books are
+// used as its contents.
+func (s *Server) AddShelf(shelf bookstack.ShelfDetailed) bookstack.ShelfDetailed {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	books := []int{}
+	for _, b := range shelf.Books {
+		books = append(books, b.ID)
+	}
+
+	return s.shelfDetailed(s.addShelf(shelf, books))
+}
+
+// Shelf returns the stored shelf with the given id.
+func (s *Server) Shelf(id int) (bookstack.ShelfDetailed, bool) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shelf, ok := s.shelves[id]
+	if !ok {
+		return bookstack.ShelfDetailed{}, false
+	}
+
+	return s.shelfDetailed(shelf), true
+}
+
+func (s *Server) addShelf(shelf bookstack.ShelfDetailed, books []int) *bookstack.ShelfDetailed {
+
+	if shelf.ID == 0 {
+		shelf.ID = s.next("shelf")
+	} else if shelf.ID > s.seq["shelf"] {
+		s.seq["shelf"] = shelf.ID
+	}
+
+	if shelf.Slug == "" {
+		shelf.Slug = slugify(shelf.Name)
+	}
+
+	if shelf.CreatedAt.IsZero() {
+		shelf.CreatedAt = s.now()
+	}
+
+	if shelf.UpdatedAt.IsZero() {
+		shelf.UpdatedAt = shelf.CreatedAt
+	}
+
+	if shelf.CreatedBy.ID == 0 {
+		shelf.CreatedBy = s.actor()
+	}
+
+	if shelf.UpdatedBy.ID == 0 {
+		shelf.UpdatedBy = bookstack.UpdatedBy(s.actor())
+	}
+
+	if shelf.OwnedBy.ID == 0 {
+		shelf.OwnedBy = bookstack.OwnedBy(s.actor())
+	}
+
+	shelf.Books = nil
+
+	s.shelves[shelf.ID] = &shelf
+	s.shelfBooks[shelf.ID] = books
+
+	return &shelf
+}
+
+func (s *Server) shelfDetailed(shelf *bookstack.ShelfDetailed) bookstack.ShelfDetailed {
+
+	result := *shelf
+	result.Books = []bookstack.Book{}
+
+	for _, id := range s.shelfBooks[shelf.ID] {
+		if b, ok := s.books[id]; ok {
+			result.Books = append(result.Books, bookSummary(b))
+		}
+	}
+
+	return result
+}
+
+func shelfSummary(d *bookstack.ShelfDetailed) bookstack.Shelf {
+	return bookstack.Shelf{
+		ID:          d.ID,
+		Name:        d.Name,
+		Slug:        d.Slug,
+		Description: d.Description,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+		CreatedBy:   d.CreatedBy.ID,
+		UpdatedBy:   d.UpdatedBy.ID,
+		OwnedBy:     d.OwnedBy.ID,
+	}
+}
+
+func (s *Server) handleShelves(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) == 1 {
+
+		switch r.method {
+		case http.MethodGet:
+
+			list := []bookstack.Shelf{}
+			for _, sh := range s.shelves {
+				list = append(list, shelfSummary(sh))
+			}
+
+			writeList(w, r, list)
+
+		case http.MethodPost:
+
+			if !r.payload.required(w, "name") {
+				return
+			}
+
+			shelf := s.addShelf(bookstack.ShelfDetailed{
+				Name:        r.payload.str("name"),
+				Description: r.payload.str("description"),
+				Tags:        r.payload.tags(),
+			}, r.payload.ints("books"))
+
+			if img, ok := r.payload.files["image"]; ok {
+				shelf.Cover = s.cover("bookshelf", shelf.ID, img)
+			}
+
+			s.audit(bookstack.AuditShelfCreate, bookstack.ContentShelf, shelf.ID, shelf.Name)
+
+			writeJSON(w, http.StatusOK, shelfSummary(shelf))
+
+		default:
+			methodNotAllowed(w)
+		}
+
+		return
+	}
+
+	id, _ := r.id(1)
+
+	shelf, ok := s.shelves[id]
+	if !ok || len(r.segs) != 2 {
+		notFound(w, "Shelf")
+		return
+	}
+
+	switch r.method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.shelfDetailed(shelf))
+
+	case http.MethodPut:
+
+		if r.payload.has("name") {
+			shelf.Name = r.payload.str("name")
+			shelf.Slug = slugify(shelf.Name)
+		}
+
+		if r.payload.has("description") {
+			shelf.Description = r.payload.str("description")
+		}
+
+		if r.payload.has("books") {
+			s.shelfBooks[shelf.ID] = r.payload.ints("books")
+		}
+
+		if r.payload.has("tags") {
+			shelf.Tags = r.payload.tags()
+		}
+
+		if img, ok := r.payload.files["image"]; ok {
+			shelf.Cover = s.cover("bookshelf", shelf.ID, img)
+		}
+
+		shelf.UpdatedAt = s.now()
+
+		s.audit(bookstack.AuditShelfUpdate, bookstack.ContentShelf, shelf.ID, shelf.Name)
+
+		writeJSON(w, http.StatusOK, shelfSummary(shelf))
+
+	case http.MethodDelete:
+		s.audit(bookstack.AuditShelfDelete, bookstack.ContentShelf, shelf.ID, shelf.Name)
+		s.deleteShelf(shelf)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
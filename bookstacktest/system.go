@@ -0,0 +1,57 @@
+package bookstacktest
+
+import (
+	"net/http"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// DefaultVersion is the BookStack version reported by a new server.
+const DefaultVersion = "v25.02"
+
+// instanceID is the id reported for the server, and recorded in its exports.
+const instanceID = "1b1e3a5c-63b4-4d38-9a6c-0e3f2f4f8a10"
+
+// SetVersion sets the version reported by the system endpoint. An empty
+// version removes the endpoint, as on servers older than v24.05, and leaves
+// the contents out of books.
+func (s *Server) SetVersion(version string) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.version = version
+}
+
+// RemoveEndpoints makes the endpoints with the given names, such as "roles"
+// or "audit-log", respond as routes that do not exist, as on servers that
+// predate them.
+func (s *Server) RemoveEndpoints(names ...string) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range names {
+		s.removed[name] = true
+	}
+}
+
+func (s *Server) handleSystem(w http.ResponseWriter, r *request) {
+
+	if s.version == "" || len(r.segs) != 1 {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	if r.method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bookstack.SystemInfo{
+		Version:    s.version,
+		InstanceID: instanceID,
+		AppName:    "BookStack",
+		BaseURL:    s.URL,
+	})
+}
@@ -0,0 +1,172 @@
+package bookstacktest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hcarriz/go-bookstack"
+)
+
+// AddUser stores a user, filling in its id, slug, urls and timestamps when
+// they are empty, and returns the stored copy.
+func (s *Server) AddUser(user bookstack.User) bookstack.User {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.addUser(user)
+}
+
+func (s *Server) addUser(user bookstack.User) *bookstack.User {
+
+	if user.ID == 0 {
+		user.ID = s.next("user")
+	} else if user.ID > s.seq["user"] {
+		s.seq["user"] = user.ID
+	}
+
+	if user.Slug == "" {
+		user.Slug = slugify(user.Name)
+	}
+
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = s.now()
+	}
+
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = user.CreatedAt
+	}
+
+	s.setUserURLs(&user)
+
+	s.users[user.ID] = &user
+
+	return &user
+}
+
+func (s *Server) setUserURLs(u *bookstack.User) {
+
+	// The server URL is not known while seeding the initial users.
+	base := ""
+	if s.Server != nil {
+		base = s.URL
+	}
+
+	u.ProfileURL = fmt.Sprintf("%s/user/%s", base, u.Slug)
+	u.EditURL = fmt.Sprintf("%s/settings/users/%d", base, u.ID)
+	u.AvatarURL = fmt.Sprintf("%s/user_avatar.png", base)
+}
+
+func (s *Server) setUserRoles(u *bookstack.User, roles []int) {
+
+	u.Roles = u.Roles[:0]
+
+	for _, id := range roles {
+
+		role, ok := s.roles[id]
+		if !ok {
+			continue
+		}
+
+		u.Roles = append(u.Roles, struct {
+			ID          int    `json:"id"`
+			DisplayName string `json:"display_name"`
+		}{ID: id, DisplayName: role.DisplayName})
+	}
+}
+
+func (s *Server) handleUsers(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) == 1 {
+
+		switch r.method {
+		case http.MethodGet:
+
+			list := []bookstack.User{}
+			for _, u := range s.users {
+				list = append(list, *u)
+			}
+
+			writeList(w, r, list)
+
+		case http.MethodPost:
+
+			if !r.payload.required(w, "name", "email") {
+				return
+			}
+
+			for _, u := range s.users {
+				if strings.EqualFold(u.Email, r.payload.str("email")) {
+					writeValidation(w, map[string][]string{"email": {"The email has already been taken."}})
+					return
+				}
+			}
+
+			user := s.addUser(bookstack.User{
+				Name:           r.payload.str("name"),
+				Email:          r.payload.str("email"),
+				ExternalAuthID: r.payload.str("external_auth_id"),
+			})
+
+			s.setUserRoles(user, r.payload.ints("roles"))
+
+			writeJSON(w, http.StatusOK, user)
+
+		default:
+			methodNotAllowed(w)
+		}
+
+		return
+	}
+
+	id, _ := r.id(1)
+
+	user, ok := s.users[id]
+	if !ok || len(r.segs) != 2 {
+		notFound(w, "User")
+		return
+	}
+
+	switch r.method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, user)
+
+	case http.MethodPut:
+
+		if r.payload.has("name") {
+			user.Name = r.payload.str("name")
+			user.Slug = slugify(user.Name)
+			s.setUserURLs(user)
+		}
+
+		if r.payload.has("email") {
+			user.Email = r.payload.str("email")
+		}
+
+		if r.payload.has("external_auth_id") {
+			user.ExternalAuthID = r.payload.str("external_auth_id")
+		}
+
+		if r.payload.has("roles") {
+			s.setUserRoles(user, r.payload.ints("roles"))
+		}
+
+		user.UpdatedAt = s.now()
+
+		writeJSON(w, http.StatusOK, user)
+
+	case http.MethodDelete:
+
+		if user.ID == 1 {
+			writeError(w, http.StatusForbidden, "You cannot delete the default admin user")
+			return
+		}
+
+		delete(s.users, user.ID)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
@@ -0,0 +1,492 @@
+package bookstacktest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/zipformat"
+)
+
+// storedImport is an uploaded ZIP waiting to be run.
+type storedImport struct {
+	bookstack.ImportDetailed
+	reader *zipformat.Reader
+}
+
+// zipExport collects the files of a ZIP export as the manifest is built.
+type zipExport struct {
+	w     *zipformat.Writer
+	err   error
+	files int
+}
+
+func (z *zipExport) add(name string, data []byte) string {
+
+	z.files++
+	name = fmt.Sprintf("%d-%s", z.files, path.Base(name))
+
+	if z.err == nil {
+		z.err = z.w.AddFile(name, bytes.NewReader(data))
+	}
+
+	return name
+}
+
+func zipTags(tags []bookstack.Tag) []zipformat.Tag {
+
+	result := []zipformat.Tag{}
+	for _, t := range tags {
+		result = append(result, zipformat.Tag{Name: t.Name, Value: t.Value})
+	}
+
+	return result
+}
+
+func bookTags(tags []zipformat.Tag) []bookstack.Tag {
+
+	result := []bookstack.Tag{}
+	for _, t := range tags {
+		result = append(result, bookstack.Tag{Name: t.Name, Value: t.Value})
+	}
+
+	return result
+}
+
+func (s *Server) zipPage(z *zipExport, p *bookstack.PageDetailed) zipformat.Page {
+
+	page := zipformat.Page{
+		ID:       p.ID,
+		Name:     p.Name,
+		HTML:     p.HTML,
+		Markdown: p.Markdown,
+		Priority: p.Priority,
+		Tags:     zipTags(p.Tags),
+	}
+
+	for _, i := range s.sortedImages() {
+		if i.UploadedTo == p.ID {
+			page.Images = append(page.Images, zipformat.Image{
+				ID:   i.ID,
+				Name: i.Name,
+				Type: string(i.Type),
+				File: z.add(path.Base(i.Path), s.imageData[i.ID]),
+			})
+		}
+	}
+
+	for _, a := range s.sortedAttachments() {
+
+		if a.UploadedTo != p.ID {
+			continue
+		}
+
+		attachment := zipformat.Attachment{ID: a.ID, Name: a.Name}
+
+		if a.External {
+			attachment.Link = a.Content
+		} else {
+			data, _ := base64.StdEncoding.DecodeString(a.Content)
+			attachment.File = z.add(a.Name+"."+a.Extension, data)
+		}
+
+		page.Attachments = append(page.Attachments, attachment)
+	}
+
+	return page
+}
+
+func (s *Server) zipChapter(z *zipExport, c *bookstack.ChapterDetailed) zipformat.Chapter {
+
+	chapter := zipformat.Chapter{
+		ID:              c.ID,
+		Name:            c.Name,
+		DescriptionHTML: descriptionHTML(c.Description),
+		Priority:        c.Priority,
+		Tags:            zipTags(c.Tags),
+	}
+
+	for _, p := range s.chapterPages(c.ID) {
+		chapter.Pages = append(chapter.Pages, s.zipPage(z, p))
+	}
+
+	return chapter
+}
+
+func descriptionHTML(description string) string {
+
+	if description == "" {
+		return ""
+	}
+
+	return "<p>" + description + "</p>"
+}
+
+func (s *Server) exportZip(w http.ResponseWriter, kind bookstack.ContentType, id int) {
+
+	var buf bytes.Buffer
+
+	z := &zipExport{w: zipformat.NewWriter(&buf)}
+
+	m := &zipformat.Manifest{
+		Instance:   zipformat.Instance{ID: instanceID, Version: s.version},
+		ExportedAt: s.now(),
+	}
+
+	slug := ""
+
+	switch kind {
+	case bookstack.ContentBook:
+
+		b := s.books[id]
+		slug = b.Slug
+
+		book := &zipformat.Book{
+			ID:              b.ID,
+			Name:            b.Name,
+			DescriptionHTML: descriptionHTML(b.Description),
+			Tags:            zipTags(b.Tags),
+		}
+
+		chapters, pages := s.bookContents(b.ID)
+
+		for _, c := range chapters {
+			book.Chapters = append(book.Chapters, s.zipChapter(z, c))
+		}
+
+		for _, p := range pages {
+			book.Pages = append(book.Pages, s.zipPage(z, p))
+		}
+
+		m.Book = book
+
+	case bookstack.ContentChapter:
+
+		c := s.chapters[id]
+		slug = c.Slug
+
+		chapter := s.zipChapter(z, c)
+		m.Chapter = &chapter
+
+	case bookstack.ContentPage:
+
+		p := s.pages[id]
+		slug = p.Slug
+
+		page := s.zipPage(z, p)
+		m.Page = &page
+	}
+
+	if z.err == nil {
+		z.err = z.w.Close(m)
+	}
+
+	if z.err != nil {
+		writeError(w, http.StatusInternalServerError, z.err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, slug))
+	w.Header().Set("Content-Length", fmt.Sprint(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+func importSummary(i *storedImport) bookstack.Import {
+	return bookstack.Import{
+		ID:        i.ID,
+		Name:      i.Name,
+		Size:      i.Size,
+		Type:      i.Type,
+		CreatedBy: i.CreatedBy,
+		CreatedAt: i.CreatedAt,
+		UpdatedAt: i.UpdatedAt,
+	}
+}
+
+func (s *Server) handleImports(w http.ResponseWriter, r *request) {
+
+	if len(r.segs) == 1 {
+
+		switch r.method {
+		case http.MethodGet:
+
+			list := []bookstack.Import{}
+			for _, i := range s.imports {
+				list = append(list, importSummary(i))
+			}
+
+			writeList(w, r, list)
+
+		case http.MethodPost:
+
+			file, ok := r.payload.files["file"]
+			if !ok {
+				writeValidation(w, map[string][]string{"file": {"The file field is required."}})
+				return
+			}
+
+			reader, err := zipformat.NewReader(bytes.NewReader(file.data), int64(len(file.data)))
+			if err != nil {
+				writeValidation(w, map[string][]string{"file": {err.Error()}})
+				return
+			}
+
+			imp := &storedImport{reader: reader}
+			imp.ID = s.next("import")
+			imp.Name = reader.Manifest.Name()
+			imp.Size = int64(len(file.data))
+			imp.Type = bookstack.ContentType(reader.Manifest.Type())
+			imp.CreatedBy = s.actor().ID
+			imp.CreatedAt = s.now()
+			imp.UpdatedAt = imp.CreatedAt
+
+			s.imports[imp.ID] = imp
+
+			writeJSON(w, http.StatusOK, importSummary(imp))
+
+		default:
+			methodNotAllowed(w)
+		}
+
+		return
+	}
+
+	id, _ := r.id(1)
+
+	imp, ok := s.imports[id]
+	if !ok || len(r.segs) != 2 {
+		notFound(w, "Import")
+		return
+	}
+
+	switch r.method {
+	case http.MethodGet:
+
+		detailed := imp.ImportDetailed
+
+		var item interface{}
+		switch {
+		case imp.reader.Manifest.Book != nil:
+			item = imp.reader.Manifest.Book
+		case imp.reader.Manifest.Chapter != nil:
+			item = imp.reader.Manifest.Chapter
+		default:
+			item = imp.reader.Manifest.Page
+		}
+
+		writeJSON(w, http.StatusOK, struct {
+			bookstack.ImportDetailed
+			Details interface{} `json:"details"`
+		}{detailed, item})
+
+	case http.MethodPost:
+		s.runImport(w, r, imp)
+
+	case http.MethodDelete:
+		delete(s.imports, imp.ID)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (s *Server) runImport(w http.ResponseWriter, r *request, imp *storedImport) {
+
+	m := imp.reader.Manifest
+
+	parentType := bookstack.ContentType(r.payload.str("parent_type"))
+	parentID := r.payload.int("parent_id")
+
+	invalidParent := func() {
+		writeValidation(w, map[string][]string{"parent_id": {"The selected parent is invalid."}})
+	}
+
+	var result interface{}
+
+	switch {
+	case m.Book != nil:
+		result = bookSummary(s.importBook(imp.reader, m.Book))
+
+	case m.Chapter != nil:
+
+		if _, ok := s.books[parentID]; parentType != bookstack.ContentBook || !ok {
+			invalidParent()
+			return
+		}
+
+		result = chapterSummary(s.importChapter(imp.reader, m.Chapter, parentID))
+
+	default:
+
+		var bookID, chapterID int
+
+		switch parentType {
+		case bookstack.ContentBook:
+			if _, ok := s.books[parentID]; ok {
+				bookID = parentID
+			}
+		case bookstack.ContentChapter:
+			if c, ok := s.chapters[parentID]; ok {
+				bookID, chapterID = c.BookID, c.ID
+			}
+		}
+
+		if bookID == 0 {
+			invalidParent()
+			return
+		}
+
+		result = s.importPage(imp.reader, m.Page, bookID, chapterID)
+	}
+
+	delete(s.imports, imp.ID)
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) importBook(zr *zipformat.Reader, b *zipformat.Book) *bookstack.BookDetailed {
+
+	book := s.addBook(bookstack.BookDetailed{
+		Name:        b.Name,
+		Description: plain(b.DescriptionHTML),
+		Tags:        bookTags(b.Tags),
+	})
+
+	s.audit(bookstack.AuditBookCreate, bookstack.ContentBook, book.ID, book.Name)
+
+	for i := range b.Chapters {
+		s.importChapter(zr, &b.Chapters[i], book.ID)
+	}
+
+	for i := range b.Pages {
+		s.importPage(zr, &b.Pages[i], book.ID, 0)
+	}
+
+	return book
+}
+
+func (s *Server) importChapter(zr *zipformat.Reader, c *zipformat.Chapter, bookID int) *bookstack.ChapterDetailed {
+
+	priority := c.Priority
+	if priority == 0 {
+		priority = s.nextPriority(bookID)
+	}
+
+	chapter := s.addChapter(bookstack.ChapterDetailed{
+		BookID:      bookID,
+		Name:        c.Name,
+		Description: plain(c.DescriptionHTML),
+		Priority:    priority,
+		Tags:        bookTags(c.Tags),
+	})
+
+	s.audit(bookstack.AuditChapterCreate, bookstack.ContentChapter, chapter.ID, chapter.Name)
+
+	for i := range c.Pages {
+		s.importPage(zr, &c.Pages[i], bookID, chapter.ID)
+	}
+
+	return chapter
+}
+
+func (s *Server) importPage(zr *zipformat.Reader, p *zipformat.Page, bookID, chapterID int) *bookstack.PageDetailed {
+
+	priority := p.Priority
+	if priority == 0 {
+		priority = s.nextPriority(bookID)
+	}
+
+	page := s.addPage(bookstack.PageDetailed{
+		BookID:    bookID,
+		ChapterID: chapterID,
+		Name:      p.Name,
+		HTML:      p.HTML,
+		Markdown:  p.Markdown,
+		Priority:  priority,
+		Tags:      bookTags(p.Tags),
+	})
+
+	s.audit(bookstack.AuditPageCreate, bookstack.ContentPage, page.ID, page.Name)
+
+	for _, i := range p.Images {
+
+		image := s.addImage(bookstack.ImageDetailed{
+			Name:       i.Name,
+			Type:       bookstack.ImageType(i.Type),
+			UploadedTo: page.ID,
+			Path:       fmt.Sprintf("/uploads/images/%s/%s/%s", i.Type, s.now().Format("2006-01"), i.File),
+		})
+
+		s.imageData[image.ID] = readZipFile(zr, i.File)
+	}
+
+	for _, a := range p.Attachments {
+
+		attachment := bookstack.AttachmentDetailed{Name: a.Name, UploadedTo: page.ID}
+
+		if a.Link != "" {
+			attachment.External = true
+			attachment.Content = a.Link
+		} else {
+			attachment.Extension = path.Ext(a.File)
+			if attachment.Extension != "" {
+				attachment.Extension = attachment.Extension[1:]
+			}
+			attachment.Content = base64.StdEncoding.EncodeToString(readZipFile(zr, a.File))
+		}
+
+		s.addAttachment(attachment)
+	}
+
+	return page
+}
+
+func (s *Server) sortedImages() []*bookstack.ImageDetailed {
+
+	list := []*bookstack.ImageDetailed{}
+	for _, i := range s.images {
+		list = append(list, i)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+func (s *Server) sortedAttachments() []*bookstack.AttachmentDetailed {
+
+	list := []*bookstack.AttachmentDetailed{}
+	for _, a := range s.attachments {
+		list = append(list, a)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Order < list[j].Order || list[i].Order == list[j].Order && list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+func readZipFile(zr *zipformat.Reader, name string) []byte {
+
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil
+	}
+
+	defer f.Close()
+
+	var buf bytes.Buffer
+	buf.ReadFrom(f)
+
+	return buf.Bytes()
+}
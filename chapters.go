@@ -61,6 +61,8 @@ type ChapterParams struct {
 	Name        string      `json:"name,omitempty"`
 	Description string      `json:"description,omitempty"`
 	Tags        []TagParams `json:"tags,omitempty"`
+	// Priority sets the position within the book. Nil leaves it as it is.
+	Priority *int `json:"priority,omitempty"`
 }
 
 func (bp ChapterParams) Form() (string, io.Reader, error) {
@@ -84,6 +86,18 @@ func (b *Bookstack) ListChapters(ctx context.Context, params *QueryParams) ([]Ch
 	return ParseMultiple[[]Chapter](resp)
 }
 
+// ListChaptersResult will return a page of the chapters that match the given params,
+// along with the total and the pagination applied.
+func (b *Bookstack) ListChaptersResult(ctx context.Context, params *QueryParams) (ListResult[Chapter], error) {
+	return List[Chapter](ctx, b, "/chapters", params)
+}
+
+// AllChapters will return every chapter that matches the given params, following
+// pagination. The Count of params sets the page size.
+func (b *Bookstack) AllChapters(ctx context.Context, params *QueryParams) ([]Chapter, error) {
+	return Paginate[Chapter](ctx, b, "/chapters", params).All()
+}
+
 // GetChapter will return a single chapter that matches id.
 func (b *Bookstack) GetChapter(ctx context.Context, id int) (ChapterDetailed, error) {
 
@@ -0,0 +1,120 @@
+package bookstack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ContentPermissions are the permissions applied to a single book, chapter,
+// page or shelf.
+type ContentPermissions struct {
+	Owner               ContentOwner        `json:"owner,omitempty"`
+	RolePermissions     []RolePermission    `json:"role_permissions,omitempty"`
+	FallbackPermissions FallbackPermissions `json:"fallback_permissions,omitempty"`
+}
+
+type ContentOwner struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// RolePermission overrides the permissions of a role for the item.
+type RolePermission struct {
+	RoleID int  `json:"role_id"`
+	View   bool `json:"view"`
+	Create bool `json:"create"`
+	Update bool `json:"update"`
+	Delete bool `json:"delete"`
+	// Role is only set in responses.
+	Role *Role `json:"role,omitempty"`
+}
+
+// FallbackPermissions apply to every role without a RolePermission. When
+// Inheriting is set, the permissions of the parent item are used instead and
+// the other fields are ignored.
+type FallbackPermissions struct {
+	Inheriting bool `json:"inheriting"`
+	View       bool `json:"view"`
+	Create     bool `json:"create"`
+	Update     bool `json:"update"`
+	Delete     bool `json:"delete"`
+}
+
+type ContentPermissionsParams struct {
+	// OwnerID is left unchanged when zero.
+	OwnerID int
+	// RolePermissions replace the existing role permissions. They are left
+	// unchanged when nil, and removed when empty.
+	RolePermissions []RolePermission
+	// FallbackPermissions are left unchanged when nil.
+	FallbackPermissions *FallbackPermissions
+}
+
+func (cp ContentPermissionsParams) Form() (string, io.Reader, error) {
+
+	body := map[string]interface{}{}
+
+	if cp.OwnerID != 0 {
+		body["owner_id"] = cp.OwnerID
+	}
+
+	if cp.RolePermissions != nil {
+
+		perms := make([]RolePermission, len(cp.RolePermissions))
+
+		for i, p := range cp.RolePermissions {
+			p.Role = nil
+			perms[i] = p
+		}
+
+		body["role_permissions"] = perms
+	}
+
+	if cp.FallbackPermissions != nil {
+		body["fallback_permissions"] = cp.FallbackPermissions
+	}
+
+	r, err := json.Marshal(body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return appJSON, bytes.NewReader(r), nil
+}
+
+// GetContentPermissions will return the permissions of the item of the given
+// content type and id.
+func (b *Bookstack) GetContentPermissions(ctx context.Context, contentType ContentType, id int) (ContentPermissions, error) {
+
+	if err := b.require(ctx, CapabilityContentPermissions); err != nil {
+		return ContentPermissions{}, err
+	}
+
+	resp, err := b.request(ctx, http.MethodGet, fmt.Sprintf("/content-permissions/%s/%d", contentType, id), blank{})
+	if err != nil {
+		return ContentPermissions{}, err
+	}
+
+	return ParseSingle[ContentPermissions](resp)
+}
+
+// UpdateContentPermissions will update the permissions of the item of the
+// given content type and id with the given params.
+func (b *Bookstack) UpdateContentPermissions(ctx context.Context, contentType ContentType, id int, params ContentPermissionsParams) (ContentPermissions, error) {
+
+	if err := b.require(ctx, CapabilityContentPermissions); err != nil {
+		return ContentPermissions{}, err
+	}
+
+	resp, err := b.request(ctx, http.MethodPut, fmt.Sprintf("/content-permissions/%s/%d", contentType, id), params)
+	if err != nil {
+		return ContentPermissions{}, err
+	}
+
+	return ParseSingle[ContentPermissions](resp)
+}
@@ -0,0 +1,88 @@
+package bookstack_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentPermissions(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Handbook"})
+	check.NoError(err)
+
+	shelf, err := bk.CreateShelf(ctx, bookstack.ShelfParams{Name: "Company", Books: []int{book.ID}})
+	check.NoError(err)
+
+	perms, err := bk.GetContentPermissions(ctx, bookstack.ContentBook, book.ID)
+	check.NoError(err)
+	check.Equal("Admin", perms.Owner.Name)
+	check.True(perms.FallbackPermissions.Inheriting)
+	check.Empty(perms.RolePermissions)
+
+	updated, err := bk.UpdateContentPermissions(ctx, bookstack.ContentBook, book.ID, bookstack.ContentPermissionsParams{
+		OwnerID: 2,
+		RolePermissions: []bookstack.RolePermission{
+			{RoleID: 3, View: true},
+			{RoleID: 2, View: true, Create: true, Update: true},
+		},
+		FallbackPermissions: &bookstack.FallbackPermissions{View: true},
+	})
+	check.NoError(err)
+	check.Equal(2, updated.Owner.ID)
+	check.False(updated.FallbackPermissions.Inheriting)
+	check.True(updated.FallbackPermissions.View)
+	check.Len(updated.RolePermissions, 2)
+	check.Equal("Editor", updated.RolePermissions[0].Role.DisplayName)
+	check.True(updated.RolePermissions[0].Update)
+	check.False(updated.RolePermissions[1].Update)
+
+	got, err := bk.GetBook(ctx, book.ID)
+	check.NoError(err)
+	check.Equal(2, got.OwnedBy.ID)
+
+	// Fields left out of the params are unchanged.
+	updated, err = bk.UpdateContentPermissions(ctx, bookstack.ContentBook, book.ID, bookstack.ContentPermissionsParams{
+		FallbackPermissions: &bookstack.FallbackPermissions{Inheriting: true},
+	})
+	check.NoError(err)
+	check.Equal(2, updated.Owner.ID)
+	check.True(updated.FallbackPermissions.Inheriting)
+	check.Len(updated.RolePermissions, 2)
+
+	updated, err = bk.UpdateContentPermissions(ctx, bookstack.ContentBook, book.ID, bookstack.ContentPermissionsParams{
+		RolePermissions: []bookstack.RolePermission{},
+	})
+	check.NoError(err)
+	check.Empty(updated.RolePermissions)
+
+	updated, err = bk.UpdateContentPermissions(ctx, bookstack.ContentShelf, shelf.ID, bookstack.ContentPermissionsParams{
+		RolePermissions: []bookstack.RolePermission{{RoleID: 4, View: true}},
+	})
+	check.NoError(err)
+	check.Equal("Public", updated.RolePermissions[0].Role.DisplayName)
+
+	_, err = bk.UpdateContentPermissions(ctx, bookstack.ContentShelf, shelf.ID, bookstack.ContentPermissionsParams{
+		RolePermissions: []bookstack.RolePermission{{RoleID: 99, View: true}},
+	})
+	check.ErrorIs(err, bookstack.ErrValidation)
+
+	_, err = bk.GetContentPermissions(ctx, bookstack.ContentChapter, 99)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+
+	entries, err := bk.ListAuditLog(ctx, &bookstack.AuditLogParams{Event: bookstack.AuditPermissionsUpdate})
+	check.NoError(err)
+	check.Len(entries, 4)
+}
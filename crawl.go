@@ -0,0 +1,439 @@
+package bookstack
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// CrawlItem describes a shelf, book, chapter or page to the filters of Crawl.
+type CrawlItem struct {
+	Type ContentType
+	ID   int
+	Name string
+	Slug string
+	// BookID is the book of a chapter or page.
+	BookID int
+	// ChapterID is the chapter of a page, if it is in one.
+	ChapterID int
+}
+
+// CrawlFilter reports whether an item matches.
+type CrawlFilter func(item CrawlItem) bool
+
+// CrawlOption configures Crawl.
+type CrawlOption func(*crawlConfig)
+
+type crawlConfig struct {
+	workers  int
+	include  []CrawlFilter
+	exclude  []CrawlFilter
+	bodies   bool
+	failFast bool
+}
+
+// CrawlWorkers sets the number of requests Crawl makes at once. It defaults
+// to 4. Requests are still held to the rate limit of the client.
+func CrawlWorkers(n int) CrawlOption {
+	return func(c *crawlConfig) {
+		c.workers = n
+	}
+}
+
+// CrawlInclude leaves out every item that f does not match. Leaving out a
+// book or chapter leaves out its contents too.
+func CrawlInclude(f CrawlFilter) CrawlOption {
+	return func(c *crawlConfig) {
+		c.include = append(c.include, f)
+	}
+}
+
+// CrawlExclude leaves out every item that f matches. Leaving out a book or
+// chapter leaves out its contents too.
+func CrawlExclude(f CrawlFilter) CrawlOption {
+	return func(c *crawlConfig) {
+		c.exclude = append(c.exclude, f)
+	}
+}
+
+// CrawlPageBodies fetches each page, so that InstancePage.Detail holds its
+// content. Otherwise only the metadata from the page list is read.
+func CrawlPageBodies() CrawlOption {
+	return func(c *crawlConfig) {
+		c.bodies = true
+	}
+}
+
+// CrawlFailFast stops the crawl at the first failed request, and returns its
+// error. Otherwise failures to read single shelves or pages are recorded in
+// Instance.Errors and the crawl carries on.
+func CrawlFailFast() CrawlOption {
+	return func(c *crawlConfig) {
+		c.failFast = true
+	}
+}
+
+func (c crawlConfig) allows(item CrawlItem) bool {
+
+	for _, f := range c.include {
+		if !f(item) {
+			return false
+		}
+	}
+
+	for _, f := range c.exclude {
+		if f(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CrawlError is an item that could not be read by Crawl.
+type CrawlError struct {
+	Type ContentType
+	ID   int
+	Err  error
+}
+
+func (e CrawlError) Error() string {
+	return fmt.Sprintf("bookstack: crawling %s %d: %v", e.Type, e.ID, e.Err)
+}
+
+func (e CrawlError) Unwrap() error {
+	return e.Err
+}
+
+// Instance is every shelf, book, chapter and page of a BookStack instance,
+// linked to their parents and children.
+type Instance struct {
+	Shelves []*InstanceShelf
+	Books   []*InstanceBook
+	// Orphans are the books that are not on any of the crawled shelves. It is
+	// left empty when a shelf could not be read, as any book may be on it.
+	Orphans []*InstanceBook
+	// Errors are the items that could not be read. The rest of the instance
+	// is complete without them.
+	Errors []CrawlError
+
+	books    map[int]*InstanceBook
+	chapters map[int]*InstanceChapter
+	pages    map[int]*InstancePage
+}
+
+type InstanceShelf struct {
+	Shelf Shelf
+	Books []*InstanceBook
+}
+
+type InstanceBook struct {
+	Book    Book
+	Shelves []*InstanceShelf
+	// Chapters and Pages are sorted by priority. Pages only holds the pages
+	// that are not in a chapter.
+	Chapters []*InstanceChapter
+	Pages    []*InstancePage
+}
+
+type InstanceChapter struct {
+	Chapter Chapter
+	Book    *InstanceBook
+	Pages   []*InstancePage
+}
+
+type InstancePage struct {
+	Page Page
+	// Detail is only set when crawling with CrawlPageBodies.
+	Detail  *PageDetailed
+	Book    *InstanceBook
+	Chapter *InstanceChapter
+}
+
+// Book returns the crawled book with the given id.
+func (i *Instance) Book(id int) (*InstanceBook, bool) {
+	b, ok := i.books[id]
+	return b, ok
+}
+
+// Chapter returns the crawled chapter with the given id.
+func (i *Instance) Chapter(id int) (*InstanceChapter, bool) {
+	c, ok := i.chapters[id]
+	return c, ok
+}
+
+// Page returns the crawled page with the given id.
+func (i *Instance) Page(id int) (*InstancePage, bool) {
+	p, ok := i.pages[id]
+	return p, ok
+}
+
+// Crawl will read every shelf, book, chapter and page of the instance into
+// an Instance. Shelves, books, chapters and pages are listed, then each shelf
+// is read for its books, and each page for its body if CrawlPageBodies is
+// given, using a pool of workers.
+func (b *Bookstack) Crawl(ctx context.Context, opts ...CrawlOption) (*Instance, error) {
+
+	config := crawlConfig{workers: 4}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.workers < 1 {
+		config.workers = 1
+	}
+
+	var (
+		shelves  []Shelf
+		books    []Book
+		chapters []Chapter
+		pages    []Page
+	)
+
+	lists := []func(context.Context) error{
+		func(ctx context.Context) (err error) {
+			shelves, err = b.AllShelves(ctx, nil)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			books, err = b.AllBooks(ctx, nil)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			chapters, err = b.AllChapters(ctx, nil)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			pages, err = b.AllPages(ctx, nil)
+			return err
+		},
+	}
+
+	// The lists are needed for the rest of the crawl, so any failure to read
+	// one ends it.
+	if err := runPool(ctx, config.workers, len(lists), func(ctx context.Context, n int) error {
+		return lists[n](ctx)
+	}); err != nil {
+		return nil, err
+	}
+
+	inst := &Instance{
+		Shelves:  []*InstanceShelf{},
+		Books:    []*InstanceBook{},
+		Orphans:  []*InstanceBook{},
+		Errors:   []CrawlError{},
+		books:    map[int]*InstanceBook{},
+		chapters: map[int]*InstanceChapter{},
+		pages:    map[int]*InstancePage{},
+	}
+
+	for _, book := range books {
+
+		if !config.allows(CrawlItem{Type: ContentBook, ID: book.ID, Name: book.Name, Slug: book.Slug}) {
+			continue
+		}
+
+		node := &InstanceBook{Book: book, Shelves: []*InstanceShelf{}, Chapters: []*InstanceChapter{}, Pages: []*InstancePage{}}
+
+		inst.Books = append(inst.Books, node)
+		inst.books[book.ID] = node
+	}
+
+	for _, chapter := range chapters {
+
+		book, ok := inst.books[chapter.BookID]
+		if !ok || !config.allows(CrawlItem{Type: ContentChapter, ID: chapter.ID, Name: chapter.Name, Slug: chapter.Slug, BookID: chapter.BookID}) {
+			continue
+		}
+
+		node := &InstanceChapter{Chapter: chapter, Book: book, Pages: []*InstancePage{}}
+
+		book.Chapters = append(book.Chapters, node)
+		inst.chapters[chapter.ID] = node
+	}
+
+	for _, page := range pages {
+
+		book, ok := inst.books[page.BookID]
+		if !ok || !config.allows(CrawlItem{Type: ContentPage, ID: page.ID, Name: page.Name, Slug: page.Slug, BookID: page.BookID, ChapterID: page.ChapterID}) {
+			continue
+		}
+
+		node := &InstancePage{Page: page, Book: book}
+
+		if page.ChapterID != 0 {
+
+			chapter, ok := inst.chapters[page.ChapterID]
+			if !ok {
+				continue
+			}
+
+			node.Chapter = chapter
+			chapter.Pages = append(chapter.Pages, node)
+
+		} else {
+			book.Pages = append(book.Pages, node)
+		}
+
+		inst.pages[page.ID] = node
+	}
+
+	for _, shelf := range shelves {
+		if config.allows(CrawlItem{Type: ContentShelf, ID: shelf.ID, Name: shelf.Name, Slug: shelf.Slug}) {
+			inst.Shelves = append(inst.Shelves, &InstanceShelf{Shelf: shelf, Books: []*InstanceBook{}})
+		}
+	}
+
+	var mu sync.Mutex
+
+	// failed records the failure to read an item, unless the crawl is to
+	// stop at it.
+	failed := func(ctx context.Context, kind ContentType, id int, err error) error {
+
+		if config.failFast || ctx.Err() != nil {
+			return err
+		}
+
+		mu.Lock()
+		inst.Errors = append(inst.Errors, CrawlError{Type: kind, ID: id, Err: err})
+		mu.Unlock()
+
+		return nil
+	}
+
+	contents := make([][]Book, len(inst.Shelves))
+	unread := false
+
+	if err := runPool(ctx, config.workers, len(inst.Shelves), func(ctx context.Context, n int) error {
+
+		shelf, err := b.GetShelf(ctx, inst.Shelves[n].Shelf.ID)
+		if err != nil {
+
+			mu.Lock()
+			unread = true
+			mu.Unlock()
+
+			return failed(ctx, ContentShelf, inst.Shelves[n].Shelf.ID, err)
+		}
+
+		contents[n] = shelf.Books
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for n, shelf := range inst.Shelves {
+		for _, book := range contents[n] {
+			if node, ok := inst.books[book.ID]; ok {
+				shelf.Books = append(shelf.Books, node)
+				node.Shelves = append(node.Shelves, shelf)
+			}
+		}
+	}
+
+	if config.bodies {
+
+		list := make([]*InstancePage, 0, len(inst.pages))
+		for _, p := range inst.pages {
+			list = append(list, p)
+		}
+
+		if err := runPool(ctx, config.workers, len(list), func(ctx context.Context, n int) error {
+
+			page, err := b.GetPage(ctx, list[n].Page.ID)
+			if err != nil {
+				return failed(ctx, ContentPage, list[n].Page.ID, err)
+			}
+
+			list[n].Detail = &page
+
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, book := range inst.Books {
+
+		if len(book.Shelves) == 0 && !unread {
+			inst.Orphans = append(inst.Orphans, book)
+		}
+
+		sort.SliceStable(book.Chapters, func(i, j int) bool {
+			return book.Chapters[i].Chapter.Priority < book.Chapters[j].Chapter.Priority
+		})
+
+		sortInstancePages(book.Pages)
+
+		for _, chapter := range book.Chapters {
+			sortInstancePages(chapter.Pages)
+		}
+	}
+
+	sort.Slice(inst.Errors, func(i, j int) bool {
+		return inst.Errors[i].Type < inst.Errors[j].Type || inst.Errors[i].Type == inst.Errors[j].Type && inst.Errors[i].ID < inst.Errors[j].ID
+	})
+
+	return inst, nil
+}
+
+func sortInstancePages(pages []*InstancePage) {
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].Page.Priority < pages[j].Page.Priority
+	})
+}
+
+// runPool calls fn for 0 to n-1 using the given number of workers. It
+// returns the first error from fn, cancelling the calls still running.
+func runPool(ctx context.Context, workers, n int, fn func(ctx context.Context, n int) error) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+		jobs  = make(chan int)
+	)
+
+	for w := 0; w < workers && w < n; w++ {
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+
+			for job := range jobs {
+				if err := fn(ctx, job); err != nil {
+					once.Do(func() {
+						first = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+send:
+	for job := 0; job < n; job++ {
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			break send
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+
+	return ctx.Err()
+}
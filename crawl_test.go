@@ -0,0 +1,141 @@
+package bookstack_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+// failingTransport answers requests for the given path with a not found error
+// instead of passing them on.
+type failingTransport struct {
+	path string
+}
+
+func (f failingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+
+	if r.URL.Path != f.path {
+		return http.DefaultTransport.RoundTrip(r)
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"error":{"code":404,"message":"Page not found"}}`)),
+		Request:    r,
+	}, nil
+}
+
+func TestCrawl(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	shelved, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Shelved"})
+	check.NoError(err)
+
+	orphan, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Orphan"})
+	check.NoError(err)
+
+	_, err = bk.CreateBook(ctx, bookstack.BookParams{Name: "Private"})
+	check.NoError(err)
+
+	shelf, err := bk.CreateShelf(ctx, bookstack.ShelfParams{Name: "Shelf", Books: []int{shelved.ID}})
+	check.NoError(err)
+
+	chapter, err := bk.CreateChapter(ctx, bookstack.ChapterParams{BookID: shelved.ID, Name: "Chapter"})
+	check.NoError(err)
+
+	for _, name := range []string{"First", "Second"} {
+		_, err = bk.CreatePage(ctx, bookstack.PageParams{ChapterID: chapter.ID, Name: name, HTML: "<p>" + name + "</p>"})
+		check.NoError(err)
+	}
+
+	direct, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: shelved.ID, Name: "Direct", HTML: "<p>Direct</p>"})
+	check.NoError(err)
+
+	broken, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: orphan.ID, Name: "Broken", HTML: "<p>Broken</p>"})
+	check.NoError(err)
+
+	private := bookstack.CrawlExclude(func(item bookstack.CrawlItem) bool {
+		return item.Type == bookstack.ContentBook && item.Name == "Private"
+	})
+
+	inst, err := bk.Crawl(ctx, private, bookstack.CrawlWorkers(2))
+	check.NoError(err)
+	check.Len(inst.Shelves, 1)
+	check.Len(inst.Books, 2)
+	check.Empty(inst.Errors)
+
+	check.Len(inst.Orphans, 1)
+	check.Equal("Orphan", inst.Orphans[0].Book.Name)
+
+	book, ok := inst.Book(shelved.ID)
+	check.True(ok)
+	check.Equal("Shelf", book.Shelves[0].Shelf.Name)
+	check.Equal(book, inst.Shelves[0].Books[0])
+	check.Len(book.Chapters, 1)
+	check.Len(book.Chapters[0].Pages, 2)
+	check.Equal("First", book.Chapters[0].Pages[0].Page.Name)
+	check.Equal(book.Chapters[0], book.Chapters[0].Pages[0].Chapter)
+	check.Len(book.Pages, 1)
+
+	page, ok := inst.Page(direct.ID)
+	check.True(ok)
+	check.Equal(book, page.Book)
+	check.Nil(page.Chapter)
+	check.Nil(page.Detail)
+
+	failing := srv.Client(bookstack.SetTransport(failingTransport{path: fmt.Sprintf("/api/pages/%d", broken.ID)}))
+
+	inst, err = failing.Crawl(ctx, private, bookstack.CrawlPageBodies())
+	check.NoError(err)
+	check.Len(inst.Errors, 1)
+	check.Equal(bookstack.ContentPage, inst.Errors[0].Type)
+	check.Equal(broken.ID, inst.Errors[0].ID)
+	check.ErrorIs(inst.Errors[0], bookstack.ErrNotFound)
+
+	page, ok = inst.Page(direct.ID)
+	check.True(ok)
+	check.Equal("<p>Direct</p>", page.Detail.HTML)
+
+	page, ok = inst.Page(broken.ID)
+	check.True(ok)
+	check.Nil(page.Detail)
+
+	_, err = failing.Crawl(ctx, bookstack.CrawlPageBodies(), bookstack.CrawlFailFast())
+	check.ErrorIs(err, bookstack.ErrNotFound)
+
+	failing = srv.Client(bookstack.SetTransport(failingTransport{path: fmt.Sprintf("/api/shelves/%d", shelf.ID)}))
+
+	inst, err = failing.Crawl(ctx, private)
+	check.NoError(err)
+	check.Len(inst.Errors, 1)
+	check.Equal(bookstack.ContentShelf, inst.Errors[0].Type)
+	check.Equal(shelf.ID, inst.Errors[0].ID)
+	check.Empty(inst.Shelves[0].Books)
+	check.Empty(inst.Orphans)
+
+	inst, err = bk.Crawl(ctx, bookstack.CrawlInclude(func(item bookstack.CrawlItem) bool {
+		return item.Type != bookstack.ContentPage || item.ChapterID != 0
+	}))
+	check.NoError(err)
+	check.Len(inst.Books, 3)
+
+	book, _ = inst.Book(shelved.ID)
+	check.Empty(book.Pages)
+	check.Len(book.Chapters[0].Pages, 2)
+}
@@ -0,0 +1,121 @@
+package bookstack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Sentinel errors matched by APIError through errors.Is.
+var (
+	ErrNotFound     = errors.New("bookstack: not found")
+	ErrUnauthorized = errors.New("bookstack: unauthorized")
+	ErrForbidden    = errors.New("bookstack: forbidden")
+	ErrValidation   = errors.New("bookstack: validation failed")
+	ErrRateLimited  = errors.New("bookstack: rate limited")
+)
+
+// ErrUnsupported is returned when the connected server is too old for the
+// requested part of the API.
+var ErrUnsupported = errors.New("bookstack: not supported by server")
+
+// APIError is returned when BookStack responds with a non-2xx status.
+// It matches the Err* sentinels with errors.Is.
+type APIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Code is the error code in the response body, usually the same as StatusCode.
+	Code int
+	// Message is the error message in the response body, or the status text
+	// when the body could not be decoded.
+	Message string
+	// Validation maps each invalid field to its messages.
+	Validation map[string][]string
+	// Method and Path identify the request that failed.
+	Method string
+	Path   string
+}
+
+func (e *APIError) Error() string {
+
+	var b strings.Builder
+
+	if e.Method != "" {
+		fmt.Fprintf(&b, "%s %s: ", e.Method, e.Path)
+	}
+
+	code := e.Code
+	if code == 0 {
+		code = e.StatusCode
+	}
+
+	fmt.Fprintf(&b, "%d %s", code, e.Message)
+
+	if len(e.Validation) > 0 {
+
+		fields := []string{}
+		for field := range e.Validation {
+			fields = append(fields, field)
+		}
+
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			fmt.Fprintf(&b, "; %s: %s", field, strings.Join(e.Validation[field], " "))
+		}
+	}
+
+	return b.String()
+}
+
+// Is reports whether the error matches one of the Err* sentinels.
+func (e *APIError) Is(target error) bool {
+
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrValidation:
+		return e.StatusCode == http.StatusUnprocessableEntity || len(e.Validation) > 0
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
+
+// newAPIError builds the error for a failed response from its raw body.
+func newAPIError(req *http.Request, resp *http.Response, raw []byte) *APIError {
+
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Code:       resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+		Method:     req.Method,
+		Path:       req.URL.Path,
+	}
+
+	msg := Response{}
+
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return e
+	}
+
+	if msg.Err.Code != 0 {
+		e.Code = msg.Err.Code
+	}
+
+	if msg.Err.Message != "" {
+		e.Message = msg.Err.Message
+	}
+
+	e.Validation = msg.Err.Validation
+
+	return e
+}
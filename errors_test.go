@@ -0,0 +1,55 @@
+package bookstack_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	_, err := bk.GetBook(ctx, 404)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+	check.NotErrorIs(err, bookstack.ErrValidation)
+
+	var apiErr *bookstack.APIError
+	check.True(errors.As(err, &apiErr))
+	check.Equal(http.StatusNotFound, apiErr.StatusCode)
+	check.Equal(http.MethodGet, apiErr.Method)
+	check.Equal("/api/books/404", apiErr.Path)
+
+	_, err = bk.CreateBook(ctx, bookstack.BookParams{})
+	check.ErrorIs(err, bookstack.ErrValidation)
+	check.True(errors.As(err, &apiErr))
+	check.Contains(apiErr.Validation, "name")
+
+	_, err = bookstack.New(bookstack.SetURL(srv.URL), bookstack.SetToken("bad", "token")).ListBooks(ctx, nil)
+	check.ErrorIs(err, bookstack.ErrUnauthorized)
+
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html><body><h1>502 Bad Gateway</h1></body></html>"))
+	}))
+	defer gateway.Close()
+
+	_, err = bookstack.New(bookstack.SetURL(gateway.URL), bookstack.SetRetryPolicy(bookstack.RetryPolicy{})).ListBooks(ctx, nil)
+	check.True(errors.As(err, &apiErr))
+	check.Equal(http.StatusBadGateway, apiErr.StatusCode)
+	check.Equal("Bad Gateway", apiErr.Message)
+}
@@ -0,0 +1,169 @@
+package bookstack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// ExportFormat is a format that books, chapters and pages can be exported
+// to.
+type ExportFormat string
+
+const (
+	ExportHTML      ExportFormat = "html"
+	ExportPDF       ExportFormat = "pdf"
+	ExportPlaintext ExportFormat = "plaintext"
+	ExportMarkdown  ExportFormat = "markdown"
+	// ExportZip is the portable format read by the zipformat package and
+	// accepted by CreateImport.
+	ExportZip ExportFormat = "zip"
+)
+
+// Export is an export streamed from the server. The caller must close Body.
+type Export struct {
+	Body io.ReadCloser
+	// ContentType is the media type of the export, such as application/pdf.
+	ContentType string
+	// Filename is the name suggested by the server, if any.
+	Filename string
+	// ContentLength is the size of the export in bytes, or -1 when unknown.
+	ContentLength int64
+}
+
+// ProgressFunc is called as an export is written, with the bytes written so
+// far and the total size, which is -1 when unknown.
+type ProgressFunc func(written, total int64)
+
+func newExport(resp *http.Response) *Export {
+
+	e := &Export{
+		Body:          resp.Body,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+	}
+
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		e.Filename = params["filename"]
+	}
+
+	return e
+}
+
+// CopyTo copies the export to w and closes Body. When progress is not nil
+// it is called after every write.
+func (e *Export) CopyTo(w io.Writer, progress ProgressFunc) (int64, error) {
+
+	defer e.Body.Close()
+
+	if progress != nil {
+		w = &progressWriter{w: w, total: e.ContentLength, progress: progress}
+	}
+
+	return io.Copy(w, e.Body)
+}
+
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+
+	n, err := p.w.Write(b)
+
+	p.written += int64(n)
+	p.progress(p.written, p.total)
+
+	return n, err
+}
+
+func (b *Bookstack) export(ctx context.Context, kind string, id int, format ExportFormat) (*Export, error) {
+
+	if format == ExportZip {
+		if err := b.require(ctx, CapabilityZipExport); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := b.do(ctx, http.MethodGet, fmt.Sprintf("/%s/%d/export/%s", kind, id, format), blank{})
+	if err != nil {
+		return nil, err
+	}
+
+	return newExport(resp), nil
+}
+
+// ExportBook will stream a book in the given format. Unlike ExportBookPDF
+// and the other format specific methods, the export is not read into memory.
+func (b *Bookstack) ExportBook(ctx context.Context, id int, format ExportFormat) (*Export, error) {
+	return b.export(ctx, "books", id, format)
+}
+
+// ExportChapter will stream a chapter in the given format.
+func (b *Bookstack) ExportChapter(ctx context.Context, id int, format ExportFormat) (*Export, error) {
+	return b.export(ctx, "chapters", id, format)
+}
+
+// ExportPage will stream a page in the given format.
+func (b *Bookstack) ExportPage(ctx context.Context, id int, format ExportFormat) (*Export, error) {
+	return b.export(ctx, "pages", id, format)
+}
+
+// ExportBookZip will stream a book, along with its images and attachments,
+// as a ZIP.
+func (b *Bookstack) ExportBookZip(ctx context.Context, id int) (*Export, error) {
+	return b.export(ctx, "books", id, ExportZip)
+}
+
+// ExportChapterZip will stream a chapter, along with its images and
+// attachments, as a ZIP.
+func (b *Bookstack) ExportChapterZip(ctx context.Context, id int) (*Export, error) {
+	return b.export(ctx, "chapters", id, ExportZip)
+}
+
+// ExportPageZip will stream a page, along with its images and attachments,
+// as a ZIP.
+func (b *Bookstack) ExportPageZip(ctx context.Context, id int) (*Export, error) {
+	return b.export(ctx, "pages", id, ExportZip)
+}
+
+// ExportBookTo will write a book in the given format to w, calling progress,
+// if not nil, as it is written.
+func (b *Bookstack) ExportBookTo(ctx context.Context, id int, format ExportFormat, w io.Writer, progress ProgressFunc) (int64, error) {
+
+	e, err := b.ExportBook(ctx, id, format)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.CopyTo(w, progress)
+}
+
+// ExportChapterTo will write a chapter in the given format to w, calling
+// progress, if not nil, as it is written.
+func (b *Bookstack) ExportChapterTo(ctx context.Context, id int, format ExportFormat, w io.Writer, progress ProgressFunc) (int64, error) {
+
+	e, err := b.ExportChapter(ctx, id, format)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.CopyTo(w, progress)
+}
+
+// ExportPageTo will write a page in the given format to w, calling progress,
+// if not nil, as it is written.
+func (b *Bookstack) ExportPageTo(ctx context.Context, id int, format ExportFormat, w io.Writer, progress ProgressFunc) (int64, error) {
+
+	e, err := b.ExportPage(ctx, id, format)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.CopyTo(w, progress)
+}
@@ -0,0 +1,76 @@
+package bookstack_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Handbook"})
+	check.NoError(err)
+
+	chapter, err := bk.CreateChapter(ctx, bookstack.ChapterParams{BookID: book.ID, Name: "Onboarding"})
+	check.NoError(err)
+
+	page, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Welcome", HTML: "<p>Hello there</p>"})
+	check.NoError(err)
+
+	export, err := bk.ExportBook(ctx, book.ID, bookstack.ExportPDF)
+	check.NoError(err)
+	check.Equal("application/pdf", export.ContentType)
+	check.Equal("handbook.pdf", export.Filename)
+
+	streamed, err := ioutil.ReadAll(export.Body)
+	check.NoError(err)
+	check.NoError(export.Body.Close())
+	check.Equal(int64(len(streamed)), export.ContentLength)
+
+	buffered, err := bk.ExportBookPDF(ctx, book.ID)
+	check.NoError(err)
+
+	raw, err := ioutil.ReadAll(buffered)
+	check.NoError(err)
+	check.Equal(raw, streamed)
+
+	var (
+		out      bytes.Buffer
+		progress []int64
+		total    int64
+	)
+
+	n, err := bk.ExportPageTo(ctx, page.ID, bookstack.ExportMarkdown, &out, func(written, size int64) {
+		progress = append(progress, written)
+		total = size
+	})
+	check.NoError(err)
+	check.Equal(int64(out.Len()), n)
+	check.Contains(out.String(), "Hello there")
+	check.NotEmpty(progress)
+	check.Equal(n, progress[len(progress)-1])
+	check.Equal(n, total)
+
+	out.Reset()
+
+	_, err = bk.ExportChapterTo(ctx, chapter.ID, bookstack.ExportHTML, &out, nil)
+	check.NoError(err)
+	check.Contains(out.String(), "<h1>Onboarding</h1>")
+
+	_, err = bk.ExportPage(ctx, 99, bookstack.ExportHTML)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+}
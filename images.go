@@ -0,0 +1,264 @@
+package bookstack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ImageType is the kind of image stored in the image gallery.
+type ImageType string
+
+const (
+	ImageGallery ImageType = "gallery"
+	ImageDrawio  ImageType = "drawio"
+)
+
+type Image struct {
+	ID         int       `json:"id,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	Type       ImageType `json:"type,omitempty"`
+	UploadedTo int       `json:"uploaded_to,omitempty"`
+	CreatedBy  int       `json:"created_by,omitempty"`
+	UpdatedBy  int       `json:"updated_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at,omitempty"`
+}
+
+type ImageDetailed struct {
+	ID         int          `json:"id,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	URL        string       `json:"url,omitempty"`
+	Path       string       `json:"path,omitempty"`
+	Type       ImageType    `json:"type,omitempty"`
+	UploadedTo int          `json:"uploaded_to,omitempty"`
+	CreatedBy  CreatedBy    `json:"created_by,omitempty"`
+	UpdatedBy  UpdatedBy    `json:"updated_by,omitempty"`
+	CreatedAt  time.Time    `json:"created_at,omitempty"`
+	UpdatedAt  time.Time    `json:"updated_at,omitempty"`
+	Thumbs     ImageThumbs  `json:"thumbs,omitempty"`
+	Content    ImageContent `json:"content,omitempty"`
+}
+
+// ImageThumbs are the URLs of the resized copies of an image.
+type ImageThumbs struct {
+	Gallery string `json:"gallery,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+// ImageContent holds snippets for embedding an image in page content.
+type ImageContent struct {
+	HTML     string `json:"html,omitempty"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type ImageParams struct {
+	// Type is required on create, and cannot be changed on update.
+	Type ImageType `json:"type,omitempty"`
+	// UploadedTo is the id of the page the image belongs to. It is required
+	// on create, and cannot be changed on update.
+	UploadedTo int    `json:"uploaded_to,omitempty"`
+	Name       string `json:"name,omitempty"`
+	// Image is the path of the file to upload. It is required on create, and
+	// replaces the existing file on update.
+	Image string `json:"-"`
+	// ImageUpload is read in place of Image when set.
+	ImageUpload *Upload `json:"-"`
+
+	method string
+}
+
+func (ip ImageParams) Form() (string, io.Reader, error) {
+
+	if ip.Image != "" || ip.ImageUpload != nil {
+
+		fields := overrideFields(ip.method)
+
+		if ip.Type != "" {
+			fields = append(fields, formField{"type", string(ip.Type)})
+		}
+
+		if ip.UploadedTo != 0 {
+			fields = append(fields, formField{"uploaded_to", strconv.Itoa(ip.UploadedTo)})
+		}
+
+		if ip.Name != "" {
+			fields = append(fields, formField{"name", ip.Name})
+		}
+
+		return multipartForm(fields, multipartFile{field: "image", path: ip.Image, upload: ip.ImageUpload})
+
+	}
+
+	r, err := json.Marshal(ip)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return appJSON, bytes.NewReader(r), nil
+}
+
+func (ip ImageParams) replayable() bool {
+	return ip.ImageUpload.replayable()
+}
+
+func (ip ImageParams) override() string {
+	return ip.method
+}
+
+// ListImages will return the gallery and drawio images that match the given
+// params.
+func (b *Bookstack) ListImages(ctx context.Context, params *QueryParams) ([]Image, error) {
+
+	if err := b.require(ctx, CapabilityImageGallery); err != nil {
+		return nil, err
+	}
+
+	resp, err := b.request(ctx, http.MethodGet, params.String("/image-gallery"), blank{})
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseMultiple[[]Image](resp)
+}
+
+// ListImagesResult will return a page of the images that match the given
+// params, along with the total and the pagination applied.
+func (b *Bookstack) ListImagesResult(ctx context.Context, params *QueryParams) (ListResult[Image], error) {
+
+	if err := b.require(ctx, CapabilityImageGallery); err != nil {
+		return ListResult[Image]{}, err
+	}
+
+	return List[Image](ctx, b, "/image-gallery", params)
+}
+
+// AllImages will return every image that matches the given params, following
+// pagination. The Count of params sets the page size.
+func (b *Bookstack) AllImages(ctx context.Context, params *QueryParams) ([]Image, error) {
+
+	if err := b.require(ctx, CapabilityImageGallery); err != nil {
+		return nil, err
+	}
+
+	return Paginate[Image](ctx, b, "/image-gallery", params).All()
+}
+
+// GetImage will return a single image, with its thumbnails and content
+// snippets, that matches id.
+func (b *Bookstack) GetImage(ctx context.Context, id int) (ImageDetailed, error) {
+
+	if err := b.require(ctx, CapabilityImageGallery); err != nil {
+		return ImageDetailed{}, err
+	}
+
+	resp, err := b.request(ctx, http.MethodGet, fmt.Sprintf("/image-gallery/%d", id), blank{})
+	if err != nil {
+		return ImageDetailed{}, err
+	}
+
+	return ParseSingle[ImageDetailed](resp)
+}
+
+// CreateImage will upload an image to the gallery according to the given
+// params.
+func (b *Bookstack) CreateImage(ctx context.Context, params ImageParams) (ImageDetailed, error) {
+
+	if err := b.require(ctx, CapabilityImageGallery); err != nil {
+		return ImageDetailed{}, err
+	}
+
+	resp, err := b.request(ctx, http.MethodPost, "/image-gallery", params)
+	if err != nil {
+		return ImageDetailed{}, err
+	}
+
+	return ParseSingle[ImageDetailed](resp)
+}
+
+// UpdateImage will rename an image, or replace its file, with the given
+// params.
+func (b *Bookstack) UpdateImage(ctx context.Context, id int, params ImageParams) (ImageDetailed, error) {
+
+	if err := b.require(ctx, CapabilityImageGallery); err != nil {
+		return ImageDetailed{}, err
+	}
+
+	var method string
+	method, params.method = overrideMethod(http.MethodPut, params.Image != "" || params.ImageUpload != nil)
+
+	resp, err := b.request(ctx, method, fmt.Sprintf("/image-gallery/%d", id), params)
+	if err != nil {
+		return ImageDetailed{}, err
+	}
+
+	return ParseSingle[ImageDetailed](resp)
+}
+
+// DeleteImage will delete an image with the given id.
+func (b *Bookstack) DeleteImage(ctx context.Context, id int) (bool, error) {
+
+	if err := b.require(ctx, CapabilityImageGallery); err != nil {
+		return false, err
+	}
+
+	if _, err := b.request(ctx, http.MethodDelete, fmt.Sprintf("/image-gallery/%d", id), blank{}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DownloadImage will write the file at the url of an image, such as
+// Image.URL, to w. Relative urls are resolved against the url of the site.
+// The token of the client is only sent to urls on the same host as the site.
+func (b *Bookstack) DownloadImage(ctx context.Context, rawURL string, w io.Writer) (int64, error) {
+
+	base, err := url.Parse(b.url)
+	if err != nil {
+		return 0, err
+	}
+
+	target, err := base.Parse(rawURL)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if target.Host == base.Host {
+		req.Header.Add("Authorization", b.authorization())
+	}
+
+	b.limit.Take()
+
+	resp, err := b.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return 0, err
+		}
+
+		return 0, newAPIError(req, resp, raw)
+	}
+
+	return io.Copy(w, resp.Body)
+}
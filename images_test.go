@@ -0,0 +1,86 @@
+package bookstack_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImages(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	dir := t.TempDir()
+
+	original := filepath.Join(dir, "diagram.png")
+	check.NoError(os.WriteFile(original, []byte("first"), 0o600))
+
+	replacement := filepath.Join(dir, "replacement.png")
+	check.NoError(os.WriteFile(replacement, []byte("second"), 0o600))
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Handbook"})
+	check.NoError(err)
+
+	page, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Welcome", HTML: "<p>Hello</p>"})
+	check.NoError(err)
+
+	_, err = bk.CreateImage(ctx, bookstack.ImageParams{Type: bookstack.ImageGallery, UploadedTo: page.ID})
+	check.ErrorIs(err, bookstack.ErrValidation)
+
+	created, err := bk.CreateImage(ctx, bookstack.ImageParams{Type: bookstack.ImageGallery, UploadedTo: page.ID, Image: original})
+	check.NoError(err)
+	check.Equal("diagram.png", created.Name)
+	check.Equal(page.ID, created.UploadedTo)
+	check.Contains(created.Thumbs.Gallery, "thumbs-150-150")
+	check.Contains(created.Content.HTML, created.Thumbs.Display)
+	check.Contains(created.Content.Markdown, created.Thumbs.Display)
+
+	drawing, err := bk.CreateImage(ctx, bookstack.ImageParams{Type: bookstack.ImageDrawio, UploadedTo: page.ID, Name: "Flow", Image: original})
+	check.NoError(err)
+	check.Equal(bookstack.ImageDrawio, drawing.Type)
+
+	renamed, err := bk.UpdateImage(ctx, created.ID, bookstack.ImageParams{Name: "Architecture"})
+	check.NoError(err)
+	check.Equal("Architecture", renamed.Name)
+	check.Equal(created.URL, renamed.URL)
+
+	replaced, err := bk.UpdateImage(ctx, created.ID, bookstack.ImageParams{Image: replacement})
+	check.NoError(err)
+	check.Equal("Architecture", replaced.Name)
+
+	_, data, ok := srv.Image(created.ID)
+	check.True(ok)
+	check.Equal("second", string(data))
+
+	got, err := bk.GetImage(ctx, created.ID)
+	check.NoError(err)
+	check.Equal(replaced.Content, got.Content)
+
+	images, err := bk.ListImages(ctx, &bookstack.QueryParams{FilterField: "type", FilterValue: string(bookstack.ImageDrawio)})
+	check.NoError(err)
+	check.Len(images, 1)
+	check.Equal(drawing.ID, images[0].ID)
+
+	deleted, err := bk.DeleteImage(ctx, drawing.ID)
+	check.NoError(err)
+	check.True(deleted)
+
+	all, err := bk.AllImages(ctx, nil)
+	check.NoError(err)
+	check.Len(all, 1)
+
+	_, err = bk.GetImage(ctx, drawing.ID)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+}
@@ -0,0 +1,180 @@
+package bookstack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Import is a ZIP uploaded to the server, waiting to be run.
+type Import struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Size is the size of the ZIP in bytes.
+	Size int64 `json:"size,omitempty"`
+	// Type is the kind of item the ZIP holds: book, chapter or page.
+	Type      ContentType `json:"type,omitempty"`
+	CreatedBy int         `json:"created_by,omitempty"`
+	CreatedAt time.Time   `json:"created_at,omitempty"`
+	UpdatedAt time.Time   `json:"updated_at,omitempty"`
+}
+
+type ImportDetailed struct {
+	ID        int         `json:"id,omitempty"`
+	Name      string      `json:"name,omitempty"`
+	Size      int64       `json:"size,omitempty"`
+	Type      ContentType `json:"type,omitempty"`
+	CreatedBy int         `json:"created_by,omitempty"`
+	CreatedAt time.Time   `json:"created_at,omitempty"`
+	UpdatedAt time.Time   `json:"updated_at,omitempty"`
+	// Details summarises the contents of the ZIP. It has the shape of the
+	// item in the manifest, and can be decoded into the matching zipformat
+	// type.
+	Details json.RawMessage `json:"details,omitempty"`
+}
+
+type ImportParams struct {
+	// File is the path of the ZIP to upload.
+	File string
+	// FileUpload is read in place of File when set.
+	FileUpload *Upload
+}
+
+func (ip ImportParams) Form() (string, io.Reader, error) {
+	return multipartForm(nil, multipartFile{field: "file", path: ip.File, upload: ip.FileUpload})
+}
+
+func (ip ImportParams) replayable() bool {
+	return ip.FileUpload.replayable()
+}
+
+type RunImportParams struct {
+	// ParentType and ParentID set where a chapter or page is imported to.
+	// Chapters must be imported to a book, and pages to a book or chapter.
+	// They are ignored for books.
+	ParentType ContentType `json:"parent_type,omitempty"`
+	ParentID   int         `json:"parent_id,omitempty"`
+}
+
+func (rp RunImportParams) Form() (string, io.Reader, error) {
+
+	r, err := json.Marshal(rp)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return appJSON, bytes.NewReader(r), nil
+}
+
+// ImportedContent is the item created by running an import.
+type ImportedContent struct {
+	ID        int       `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Slug      string    `json:"slug,omitempty"`
+	BookID    int       `json:"book_id,omitempty"`
+	ChapterID int       `json:"chapter_id,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// ListImports will return the imports that match the given params.
+func (b *Bookstack) ListImports(ctx context.Context, params *QueryParams) ([]Import, error) {
+
+	if err := b.require(ctx, CapabilityImports); err != nil {
+		return nil, err
+	}
+
+	resp, err := b.request(ctx, http.MethodGet, params.String("/imports"), blank{})
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseMultiple[[]Import](resp)
+}
+
+// ListImportsResult will return a page of the imports that match the given
+// params, along with the total and the pagination applied.
+func (b *Bookstack) ListImportsResult(ctx context.Context, params *QueryParams) (ListResult[Import], error) {
+
+	if err := b.require(ctx, CapabilityImports); err != nil {
+		return ListResult[Import]{}, err
+	}
+
+	return List[Import](ctx, b, "/imports", params)
+}
+
+// AllImports will return every import that matches the given params,
+// following pagination. The Count of params sets the page size.
+func (b *Bookstack) AllImports(ctx context.Context, params *QueryParams) ([]Import, error) {
+
+	if err := b.require(ctx, CapabilityImports); err != nil {
+		return nil, err
+	}
+
+	return Paginate[Import](ctx, b, "/imports", params).All()
+}
+
+// GetImport will return a single import, with a summary of its contents,
+// that matches id.
+func (b *Bookstack) GetImport(ctx context.Context, id int) (ImportDetailed, error) {
+
+	if err := b.require(ctx, CapabilityImports); err != nil {
+		return ImportDetailed{}, err
+	}
+
+	resp, err := b.request(ctx, http.MethodGet, fmt.Sprintf("/imports/%d", id), blank{})
+	if err != nil {
+		return ImportDetailed{}, err
+	}
+
+	return ParseSingle[ImportDetailed](resp)
+}
+
+// CreateImport will upload a ZIP to be imported. The ZIP is validated by the
+// server, but nothing is imported until RunImport is called.
+func (b *Bookstack) CreateImport(ctx context.Context, params ImportParams) (Import, error) {
+
+	if err := b.require(ctx, CapabilityImports); err != nil {
+		return Import{}, err
+	}
+
+	resp, err := b.request(ctx, http.MethodPost, "/imports", params)
+	if err != nil {
+		return Import{}, err
+	}
+
+	return ParseSingle[Import](resp)
+}
+
+// RunImport will import the contents of an import, and delete the import.
+func (b *Bookstack) RunImport(ctx context.Context, id int, params RunImportParams) (ImportedContent, error) {
+
+	if err := b.require(ctx, CapabilityImports); err != nil {
+		return ImportedContent{}, err
+	}
+
+	resp, err := b.request(ctx, http.MethodPost, fmt.Sprintf("/imports/%d", id), params)
+	if err != nil {
+		return ImportedContent{}, err
+	}
+
+	return ParseSingle[ImportedContent](resp)
+}
+
+// DeleteImport will delete an import with the given id without running it.
+func (b *Bookstack) DeleteImport(ctx context.Context, id int) (bool, error) {
+
+	if err := b.require(ctx, CapabilityImports); err != nil {
+		return false, err
+	}
+
+	if _, err := b.request(ctx, http.MethodDelete, fmt.Sprintf("/imports/%d", id), blank{}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
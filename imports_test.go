@@ -0,0 +1,173 @@
+package bookstack_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/hcarriz/go-bookstack/zipformat"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZipExportImport(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	dir := t.TempDir()
+
+	logo := filepath.Join(dir, "logo.png")
+	check.NoError(os.WriteFile(logo, []byte("logo"), 0o600))
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Handbook"})
+	check.NoError(err)
+
+	chapter, err := bk.CreateChapter(ctx, bookstack.ChapterParams{BookID: book.ID, Name: "Onboarding", Tags: []bookstack.TagParams{{Name: "team", Value: "ops"}}})
+	check.NoError(err)
+
+	page, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Welcome", HTML: "<p>Hello there</p>"})
+	check.NoError(err)
+
+	_, err = bk.CreateImage(ctx, bookstack.ImageParams{Type: bookstack.ImageGallery, UploadedTo: page.ID, Image: logo})
+	check.NoError(err)
+
+	_, err = bk.CreateAttachment(ctx, bookstack.AttachmentParams{Name: "Logo", UploadedTo: page.ID, File: logo})
+	check.NoError(err)
+
+	_, err = bk.CreateAttachment(ctx, bookstack.AttachmentParams{Name: "Site", UploadedTo: page.ID, Link: "https://example.com"})
+	check.NoError(err)
+
+	export, err := bk.ExportBookZip(ctx, book.ID)
+	check.NoError(err)
+	check.Equal("application/zip", export.ContentType)
+	check.Equal("handbook.zip", export.Filename)
+
+	path := filepath.Join(dir, export.Filename)
+
+	f, err := os.Create(path)
+	check.NoError(err)
+
+	_, err = export.CopyTo(f, nil)
+	check.NoError(err)
+	check.NoError(f.Close())
+
+	zr, err := zipformat.OpenReader(path)
+	check.NoError(err)
+	check.Equal("Handbook", zr.Manifest.Book.Name)
+	check.Equal("Onboarding", zr.Manifest.Book.Chapters[0].Name)
+	check.Len(zr.Manifest.Book.Pages[0].Images, 1)
+	check.Len(zr.Manifest.Book.Pages[0].Attachments, 2)
+	check.Len(zr.Files(), 2)
+	check.NoError(zr.Close())
+
+	imp, err := bk.CreateImport(ctx, bookstack.ImportParams{File: path})
+	check.NoError(err)
+	check.Equal(bookstack.ContentBook, imp.Type)
+	check.Equal("Handbook", imp.Name)
+
+	imports, err := bk.ListImports(ctx, nil)
+	check.NoError(err)
+	check.Len(imports, 1)
+
+	detailed, err := bk.GetImport(ctx, imp.ID)
+	check.NoError(err)
+
+	details := zipformat.Book{}
+	check.NoError(json.Unmarshal(detailed.Details, &details))
+	check.Equal("Welcome", details.Pages[0].Name)
+
+	imported, err := bk.RunImport(ctx, imp.ID, bookstack.RunImportParams{})
+	check.NoError(err)
+	check.NotEqual(book.ID, imported.ID)
+	check.Equal("Handbook", imported.Name)
+
+	pages, err := bk.ListPages(ctx, &bookstack.QueryParams{FilterField: "book_id", FilterValue: "2"})
+	check.NoError(err)
+	check.Len(pages, 1)
+
+	attachments, err := bk.ListAttachments(ctx, &bookstack.QueryParams{FilterField: "uploaded_to", FilterValue: "2"})
+	check.NoError(err)
+	check.Len(attachments, 2)
+
+	imports, err = bk.ListImports(ctx, nil)
+	check.NoError(err)
+	check.Empty(imports)
+
+	chapterZip := filepath.Join(dir, "chapter.zip")
+
+	_, err = bk.ExportChapterTo(ctx, chapter.ID, bookstack.ExportZip, mustCreate(t, chapterZip), nil)
+	check.NoError(err)
+
+	imp, err = bk.CreateImport(ctx, bookstack.ImportParams{File: chapterZip})
+	check.NoError(err)
+	check.Equal(bookstack.ContentChapter, imp.Type)
+
+	_, err = bk.RunImport(ctx, imp.ID, bookstack.RunImportParams{})
+	check.ErrorIs(err, bookstack.ErrValidation)
+
+	importedChapter, err := bk.RunImport(ctx, imp.ID, bookstack.RunImportParams{ParentType: bookstack.ContentBook, ParentID: imported.ID})
+	check.NoError(err)
+	check.Equal(imported.ID, importedChapter.BookID)
+
+	got, err := bk.GetChapter(ctx, importedChapter.ID)
+	check.NoError(err)
+	check.Equal("team", got.Tags[0].Name)
+
+	pageZip := filepath.Join(dir, "page.zip")
+
+	_, err = bk.ExportPageTo(ctx, page.ID, bookstack.ExportZip, mustCreate(t, pageZip), nil)
+	check.NoError(err)
+
+	imp, err = bk.CreateImport(ctx, bookstack.ImportParams{File: pageZip})
+	check.NoError(err)
+
+	deleted, err := bk.DeleteImport(ctx, imp.ID)
+	check.NoError(err)
+	check.True(deleted)
+
+	_, err = bk.GetImport(ctx, imp.ID)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+
+	_, err = bk.CreateImport(ctx, bookstack.ImportParams{File: logo})
+	check.ErrorIs(err, bookstack.ErrValidation)
+}
+
+func TestZipExportUnsupported(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	srv.SetVersion("v24.12")
+
+	bk := srv.Client()
+
+	_, err := bk.ExportBookZip(ctx, 1)
+	check.ErrorIs(err, bookstack.ErrUnsupported)
+
+	_, err = bk.ListImports(ctx, nil)
+	check.ErrorIs(err, bookstack.ErrUnsupported)
+}
+
+func mustCreate(t *testing.T, path string) *os.File {
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { f.Close() })
+
+	return f
+}
@@ -0,0 +1,135 @@
+// Package localfs holds the handling of local directories shared by the
+// mirror, publish and sync packages: front matter, path segments, and the
+// state files that record what a run wrote.
+package localfs
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Segment returns slug as a single path element, falling back to id for
+// slugs that are not safe to use.
+func Segment(slug string, id int) string {
+
+	if slug == "" || strings.ContainsAny(slug, `/\`) || strings.HasPrefix(slug, ".") {
+		return strconv.Itoa(id)
+	}
+
+	return slug
+}
+
+// ParseFrontMatter decodes the YAML front matter at the start of raw into
+// front, and returns the rest of raw with surrounding space trimmed. Files
+// without front matter leave front as it is.
+func ParseFrontMatter(raw []byte, front interface{}) (string, error) {
+
+	text := strings.ReplaceAll(string(raw), "\r\n", "\n")
+
+	if strings.HasPrefix(text, "---\n") {
+
+		rest := text[3:]
+		if !strings.HasSuffix(rest, "\n") {
+			rest += "\n"
+		}
+
+		end := strings.Index(rest, "\n---\n")
+		if end < 0 {
+			return "", errors.New("front matter is not closed")
+		}
+
+		if err := yaml.Unmarshal([]byte(rest[:end]), front); err != nil {
+			return "", err
+		}
+
+		text = rest[end+5:]
+	}
+
+	return strings.TrimSpace(text), nil
+}
+
+// Render returns body with front written before it as YAML front matter.
+func Render(front interface{}, body string) ([]byte, error) {
+
+	header, err := yaml.Marshal(front)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte("---\n" + string(header) + "---\n\n" + body), nil
+}
+
+// WriteFile writes content to the file at target, relative to dir, creating
+// its directories.
+func WriteFile(dir, target string, content []byte) error {
+
+	p := filepath.Join(dir, filepath.FromSlash(target))
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(p, content, 0o644)
+}
+
+// Remove removes the file at target, relative to dir, and any directories
+// left empty by it, and reports whether the file was removed.
+func Remove(dir, target string) bool {
+
+	if err := os.Remove(filepath.Join(dir, filepath.FromSlash(target))); err != nil {
+		return false
+	}
+
+	RemoveDirs(dir, target)
+
+	return true
+}
+
+// RemoveDirs removes the directories of target, relative to dir, that are
+// empty.
+func RemoveDirs(dir, target string) {
+
+	for d := path.Dir(target); d != "." && d != "/"; d = path.Dir(d) {
+		if os.Remove(filepath.Join(dir, filepath.FromSlash(d))) != nil {
+			break
+		}
+	}
+}
+
+// LoadState decodes the JSON state file name in dir into v. A missing file
+// leaves v as it is.
+func LoadState(dir, name string, v interface{}) error {
+
+	raw, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// SaveState writes v as the JSON state file name in dir.
+func SaveState(dir, name string, v interface{}) error {
+
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, name), raw, 0o644)
+}
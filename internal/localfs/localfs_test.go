@@ -0,0 +1,82 @@
+package localfs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack/internal/localfs"
+	"github.com/stretchr/testify/require"
+)
+
+type front struct {
+	ID   int    `yaml:"id,omitempty"`
+	Name string `yaml:"name,omitempty"`
+}
+
+func TestFrontMatter(t *testing.T) {
+
+	check := require.New(t)
+
+	content, err := localfs.Render(front{ID: 3, Name: "Intro"}, "Hello.\n")
+	check.NoError(err)
+	check.Equal("---\nid: 3\nname: Intro\n---\n\nHello.\n", string(content))
+
+	f := front{}
+
+	body, err := localfs.ParseFrontMatter(content, &f)
+	check.NoError(err)
+	check.Equal(front{ID: 3, Name: "Intro"}, f)
+	check.Equal("Hello.", body)
+
+	f = front{}
+
+	body, err = localfs.ParseFrontMatter([]byte("---\r\nid: 4\r\n---"), &f)
+	check.NoError(err)
+	check.Equal(4, f.ID)
+	check.Empty(body)
+
+	body, err = localfs.ParseFrontMatter([]byte("  No header. "), &f)
+	check.NoError(err)
+	check.Equal("No header.", body)
+
+	_, err = localfs.ParseFrontMatter([]byte("---\nid: 5\n"), &f)
+	check.ErrorContains(err, "front matter is not closed")
+}
+
+func TestSegment(t *testing.T) {
+
+	check := require.New(t)
+
+	check.Equal("intro", localfs.Segment("intro", 7))
+	check.Equal("7", localfs.Segment("", 7))
+	check.Equal("7", localfs.Segment("a/b", 7))
+	check.Equal("7", localfs.Segment(".hidden", 7))
+}
+
+func TestFiles(t *testing.T) {
+
+	check := require.New(t)
+
+	dir := t.TempDir()
+
+	check.NoError(localfs.WriteFile(dir, "a/b/c.md", []byte("c")))
+	check.NoError(localfs.WriteFile(dir, "a/d.md", []byte("d")))
+
+	check.True(localfs.Remove(dir, "a/b/c.md"))
+	check.False(localfs.Remove(dir, "a/b/c.md"))
+	check.NoDirExists(filepath.Join(dir, "a/b"))
+	check.FileExists(filepath.Join(dir, "a/d.md"))
+
+	state := map[string]int{}
+
+	check.NoError(localfs.LoadState(dir, "state.json", &state))
+	check.Empty(state)
+
+	check.NoError(localfs.SaveState(dir, "state.json", map[string]int{"a": 1}))
+	check.NoError(localfs.LoadState(dir, "state.json", &state))
+	check.Equal(map[string]int{"a": 1}, state)
+
+	check.NoError(os.WriteFile(filepath.Join(dir, "state.json"), []byte("{"), 0o644))
+	check.Error(localfs.LoadState(dir, "state.json", &state))
+}
@@ -0,0 +1,126 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// defaultCount is the page size BookStack uses when no count is given.
+const defaultCount = 100
+
+// ListResult is a single page of a list endpoint along with the pagination
+// that produced it.
+type ListResult[T any] struct {
+	Items []T
+	// Total is the number of items matching the query across all pages.
+	Total int
+	// Offset and Count are the offset and page size that were applied. Count
+	// is at most 500, the largest page the server returns.
+	Offset int
+	Count  int
+
+	params QueryParams
+}
+
+// List returns a single page of the list endpoint at path, such as "/books".
+func List[T any](ctx context.Context, b *Bookstack, path string, params *QueryParams) (ListResult[T], error) {
+
+	result := ListResult[T]{}
+
+	if params != nil {
+		result.params = *params
+	}
+
+	if result.params.Count <= 0 {
+		result.params.Count = defaultCount
+	}
+
+	if result.params.Count > maxCount {
+		result.params.Count = maxCount
+	}
+
+	result.Offset = result.params.Offset
+	result.Count = result.params.Count
+
+	raw, err := b.request(ctx, http.MethodGet, result.params.String(path), blank{})
+	if err != nil {
+		return result, err
+	}
+
+	r := Response{}
+
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return result, err
+	}
+
+	result.Total = r.Total
+
+	if err := json.Unmarshal(r.Data, &result.Items); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// HasNext reports whether there are items after this page.
+func (l ListResult[T]) HasNext() bool {
+	return l.Offset+l.Count < l.Total
+}
+
+// HasPrevious reports whether there are items before this page.
+func (l ListResult[T]) HasPrevious() bool {
+	return l.Offset > 0
+}
+
+// Next returns the params for the following page, or nil if this is the
+// last page.
+func (l ListResult[T]) Next() *QueryParams {
+
+	if !l.HasNext() {
+		return nil
+	}
+
+	next := l.params
+	next.Offset = l.Offset + l.Count
+
+	return &next
+}
+
+// Previous returns the params for the preceding page, or nil if this is the
+// first page.
+func (l ListResult[T]) Previous() *QueryParams {
+
+	if !l.HasPrevious() {
+		return nil
+	}
+
+	prev := l.params
+	prev.Offset = l.Offset - l.Count
+
+	if prev.Offset < 0 {
+		prev.Offset = 0
+	}
+
+	return &prev
+}
+
+// Page returns the number of this page, counting from 1.
+func (l ListResult[T]) Page() int {
+
+	if l.Count == 0 {
+		return 1
+	}
+
+	return l.Offset/l.Count + 1
+}
+
+// Pages returns the number of pages needed to list every item.
+func (l ListResult[T]) Pages() int {
+
+	if l.Count == 0 {
+		return 0
+	}
+
+	return (l.Total + l.Count - 1) / l.Count
+}
@@ -0,0 +1,79 @@
+package bookstack_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListResult(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	book := srv.AddBook(bookstack.BookDetailed{Name: "Book"})
+
+	for i := 0; i < 25; i++ {
+		srv.AddPage(bookstack.PageDetailed{BookID: book.ID, Name: fmt.Sprintf("Page %02d", i), HTML: "<p>body</p>"})
+	}
+
+	bk := srv.Client()
+
+	first, err := bk.ListPagesResult(ctx, &bookstack.QueryParams{Count: 10, SortField: "name"})
+	check.NoError(err)
+	check.Len(first.Items, 10)
+	check.Equal(25, first.Total)
+	check.Equal(0, first.Offset)
+	check.Equal(10, first.Count)
+	check.Equal(1, first.Page())
+	check.Equal(3, first.Pages())
+	check.False(first.HasPrevious())
+	check.Nil(first.Previous())
+
+	next := first.Next()
+	check.NotNil(next)
+	check.Equal(10, next.Offset)
+	check.Equal("name", next.SortField)
+
+	second, err := bk.ListPagesResult(ctx, next)
+	check.NoError(err)
+	check.Equal("Page 10", second.Items[0].Name)
+	check.Equal(0, second.Previous().Offset)
+
+	last, err := bk.ListPagesResult(ctx, second.Next())
+	check.NoError(err)
+	check.Len(last.Items, 5)
+	check.Equal(3, last.Page())
+	check.False(last.HasNext())
+	check.Nil(last.Next())
+
+	for i := 0; i < 600; i++ {
+		srv.AddPage(bookstack.PageDetailed{BookID: book.ID, Name: fmt.Sprintf("Extra %03d", i), HTML: "<p>body</p>"})
+	}
+
+	// The page size is lowered to what the server returns.
+	large, err := bk.ListPagesResult(ctx, &bookstack.QueryParams{Count: 1000})
+	check.NoError(err)
+	check.Len(large.Items, 500)
+	check.Equal(500, large.Count)
+	check.Equal(2, large.Pages())
+	check.Equal(500, large.Next().Offset)
+
+	rest, err := bk.ListPagesResult(ctx, large.Next())
+	check.NoError(err)
+	check.Len(rest.Items, 125)
+	check.False(rest.HasNext())
+
+	defaults, err := bk.ListBooksResult(ctx, nil)
+	check.NoError(err)
+	check.Equal(100, defaults.Count)
+	check.Equal(1, defaults.Total)
+}
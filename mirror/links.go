@@ -0,0 +1,194 @@
+package mirror
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/internal/localfs"
+)
+
+// linker knows where everything of an instance is written in a mirror, and
+// rewrites links to the instance into links within the mirror.
+type linker struct {
+	base     string
+	pattern  *regexp.Regexp
+	pages    map[int]string
+	chapters map[int]string
+	books    map[int]string
+	shelves  map[int]string
+	// routes maps the paths of the web routes of the instance, such as
+	// books/{book}/page/{page}, to paths in the mirror.
+	routes map[string]string
+}
+
+func (m *Mirror) newLinker(inst *bookstack.Instance) *linker {
+
+	base := strings.TrimRight(m.client.URL(), "/")
+
+	l := &linker{
+		base:     base,
+		pattern:  regexp.MustCompile(regexp.QuoteMeta(base) + `(/[^\s"'<>()\[\]]*)`),
+		pages:    map[int]string{},
+		chapters: map[int]string{},
+		books:    map[int]string{},
+		shelves:  map[int]string{},
+		routes:   map[string]string{},
+	}
+
+	ext := m.format.ext()
+
+	for _, book := range inst.Books {
+
+		dir := path.Join("books", localfs.Segment(book.Book.Slug, book.Book.ID))
+
+		l.books[book.Book.ID] = path.Join(dir, "_index"+ext)
+		l.routes["books/"+book.Book.Slug] = l.books[book.Book.ID]
+
+		for _, p := range book.Pages {
+			l.pages[p.Page.ID] = path.Join(dir, localfs.Segment(p.Page.Slug, p.Page.ID)+ext)
+			l.routes["books/"+book.Book.Slug+"/page/"+p.Page.Slug] = l.pages[p.Page.ID]
+		}
+
+		for _, c := range book.Chapters {
+
+			chapterDir := path.Join(dir, localfs.Segment(c.Chapter.Slug, c.Chapter.ID))
+
+			l.chapters[c.Chapter.ID] = path.Join(chapterDir, "_index"+ext)
+			l.routes["books/"+book.Book.Slug+"/chapter/"+c.Chapter.Slug] = l.chapters[c.Chapter.ID]
+
+			for _, p := range c.Pages {
+				l.pages[p.Page.ID] = path.Join(chapterDir, localfs.Segment(p.Page.Slug, p.Page.ID)+ext)
+				l.routes["books/"+book.Book.Slug+"/page/"+p.Page.Slug] = l.pages[p.Page.ID]
+			}
+		}
+	}
+
+	for _, shelf := range inst.Shelves {
+		l.shelves[shelf.Shelf.ID] = path.Join("shelves", localfs.Segment(shelf.Shelf.Slug, shelf.Shelf.ID)+ext)
+		l.routes["shelves/"+shelf.Shelf.Slug] = l.shelves[shelf.Shelf.ID]
+	}
+
+	return l
+}
+
+// images returns the paths of the uploaded images linked to in body.
+func (l *linker) images(body string) []string {
+
+	seen := map[string]bool{}
+	images := []string{}
+
+	for _, match := range l.pattern.FindAllStringSubmatch(body, -1) {
+
+		p, _ := splitSuffix(match[1])
+
+		if strings.HasPrefix(p, "/uploads/images/") && !seen[p] {
+			seen[p] = true
+			images = append(images, p)
+		}
+	}
+
+	return images
+}
+
+// rewrite replaces the links in body, of the file at from, to anything in
+// the mirror with relative links. files maps the paths of attachments and
+// images on the instance to where they were downloaded. It also returns
+// where the other links to the instance led, by their path, with an empty
+// target for those outside of the mirror.
+func (l *linker) rewrite(from, body string, files map[string]string) (string, map[string]string) {
+
+	links := map[string]string{}
+
+	body = l.pattern.ReplaceAllStringFunc(body, func(match string) string {
+
+		p, suffix := splitSuffix(strings.TrimPrefix(match, l.base))
+
+		if target, ok := files[p]; ok {
+			return relative(from, target) + suffix
+		}
+
+		target, ok := l.resolve(p)
+		links[p] = target
+
+		if ok {
+			return relative(from, target) + suffix
+		}
+
+		return match
+	})
+
+	return body, links
+}
+
+// resolve returns where the item at the path p of the instance, such as
+// /link/12, is in the mirror.
+func (l *linker) resolve(p string) (string, bool) {
+
+	route := strings.Trim(p, "/")
+
+	if id := strings.TrimPrefix(route, "link/"); id != route {
+		if n, err := strconv.Atoi(id); err == nil {
+			if target, ok := l.pages[n]; ok {
+				return target, true
+			}
+		}
+	}
+
+	target, ok := l.routes[route]
+
+	return target, ok
+}
+
+// moved reports whether any of the links returned by rewrite now lead
+// elsewhere.
+func (l *linker) moved(links map[string]string) bool {
+
+	for p, target := range links {
+		if now, _ := l.resolve(p); now != target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitSuffix splits a link into its path and any query and fragment.
+func splitSuffix(link string) (string, string) {
+
+	if i := strings.IndexAny(link, "?#"); i >= 0 {
+		return link[:i], link[i:]
+	}
+
+	return link, ""
+}
+
+// relative returns the link from the file at from to the file at to.
+func relative(from, to string) string {
+
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(to))
+	if err != nil {
+		return to
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// safeName returns the file name of an attachment.
+func safeName(name, extension string) string {
+
+	name = strings.TrimLeft(strings.NewReplacer("/", "-", `\`, "-").Replace(name), ".")
+
+	if name == "" {
+		name = "file"
+	}
+
+	if extension != "" && !strings.EqualFold(path.Ext(name), "."+extension) {
+		name += "." + extension
+	}
+
+	return name
+}
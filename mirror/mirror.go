@@ -0,0 +1,544 @@
+// Package mirror writes a read-only copy of a BookStack instance to a
+// directory, as Markdown or HTML files with YAML front matter. The layout of
+// a mirror is:
+//
+//	shelves/{shelf}.md
+//	books/{book}/_index.md
+//	books/{book}/{page}.md
+//	books/{book}/{chapter}/_index.md
+//	books/{book}/{chapter}/{page}.md
+//	books/{book}/{chapter}/{page}_files/
+//
+// Attachments and images of a page are downloaded to the _files directory
+// beside it, and links between pages, chapters, books, shelves, attachments
+// and images of the instance are rewritten to relative paths. Running a
+// mirror again only fetches the pages that were updated since the last run,
+// or that link to a page, chapter, book or shelf that moved.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/internal/localfs"
+)
+
+// StateFile is the name of the file, in the root of a mirror, that records
+// what was written by the last run.
+const StateFile = ".mirror.json"
+
+// Format is the format pages are written in.
+type Format string
+
+const (
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+)
+
+func (f Format) ext() string {
+
+	if f == HTML {
+		return ".html"
+	}
+
+	return ".md"
+}
+
+// FrontMatter is the YAML header of every file written to a mirror.
+type FrontMatter struct {
+	ID        int                   `yaml:"id"`
+	Type      bookstack.ContentType `yaml:"type"`
+	Name      string                `yaml:"name"`
+	Slug      string                `yaml:"slug"`
+	Tags      []Tag                 `yaml:"tags,omitempty"`
+	UpdatedAt time.Time             `yaml:"updated_at"`
+	// Links are the external attachments of a page.
+	Links []Link `yaml:"links,omitempty"`
+}
+
+type Tag struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value,omitempty"`
+}
+
+type Link struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// Option configures a Mirror.
+type Option func(*Mirror)
+
+// SetFormat sets the format pages are written in. It defaults to Markdown.
+// Changing the format of an existing mirror fetches every page again.
+func SetFormat(f Format) Option {
+	return func(m *Mirror) {
+		m.format = f
+	}
+}
+
+// Mirror copies a BookStack instance to a directory.
+type Mirror struct {
+	client *bookstack.Bookstack
+	dir    string
+	format Format
+}
+
+// New returns a mirror of the instance of client in dir.
+func New(client *bookstack.Bookstack, dir string, opts ...Option) *Mirror {
+
+	m := &Mirror{
+		client: client,
+		dir:    dir,
+		format: Markdown,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Result summarises a run of a mirror.
+type Result struct {
+	// Fetched are the ids of the pages that were written.
+	Fetched []int
+	// Skipped is the number of pages that had not changed.
+	Skipped int
+	// Removed is the number of files removed because what they held was
+	// deleted, moved or renamed.
+	Removed int
+}
+
+type state struct {
+	Format  Format            `json:"format"`
+	Pages   map[int]pageState `json:"pages"`
+	Indexes []string          `json:"indexes"`
+}
+
+type pageState struct {
+	Path      string    `json:"path"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Assets    []string  `json:"assets,omitempty"`
+	// Links maps the links of the page to the instance to where they led.
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// Run will bring the mirror up to date with the instance. The state of the
+// mirror is saved even when Run fails, so that a later run carries on from
+// the pages that were written.
+func (m *Mirror) Run(ctx context.Context) (result Result, err error) {
+
+	prev, err := m.loadState()
+	if err != nil {
+		return result, err
+	}
+
+	inst, err := m.client.Crawl(ctx, bookstack.CrawlFailFast())
+	if err != nil {
+		return result, err
+	}
+
+	next := &state{Format: m.format, Pages: map[int]pageState{}, Indexes: []string{}}
+
+	defer func() {
+
+		// Pages that were not reached keep their previous state, so their
+		// files are not forgotten.
+		for id, p := range prev.Pages {
+			if _, ok := next.Pages[id]; !ok && err != nil {
+				next.Pages[id] = p
+			}
+		}
+
+		if saveErr := m.saveState(next); err == nil {
+			err = saveErr
+		}
+	}()
+
+	l := m.newLinker(inst)
+
+	for _, book := range inst.Books {
+
+		for _, page := range allPages(book) {
+
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+
+			target := l.pages[page.Page.ID]
+			old, seen := prev.Pages[page.Page.ID]
+
+			// Pages are written again when what they link to moved, as
+			// their relative links would be broken.
+			if seen && prev.Format == m.format && old.Path == target && old.UpdatedAt.Equal(page.Page.UpdatedAt) && !l.moved(old.Links) && m.exists(target) {
+				next.Pages[page.Page.ID] = old
+				result.Skipped++
+				continue
+			}
+
+			written, err := m.writePage(ctx, l, page, target)
+			if err != nil {
+				return result, fmt.Errorf("mirror: page %d: %w", page.Page.ID, err)
+			}
+
+			next.Pages[page.Page.ID] = written
+			result.Fetched = append(result.Fetched, page.Page.ID)
+
+			if seen {
+				result.Removed += m.removeStale(old, written)
+			}
+		}
+	}
+
+	for id, old := range prev.Pages {
+		if _, ok := next.Pages[id]; !ok {
+			result.Removed += m.removeStale(old, pageState{})
+		}
+	}
+
+	indexes, err := m.writeIndexes(l, inst)
+	if err != nil {
+		return result, err
+	}
+
+	next.Indexes = indexes
+
+	written := map[string]bool{}
+	for _, f := range indexes {
+		written[f] = true
+	}
+
+	for _, f := range prev.Indexes {
+		if !written[f] && localfs.Remove(m.dir, f) {
+			result.Removed++
+		}
+	}
+
+	return result, nil
+}
+
+func allPages(book *bookstack.InstanceBook) []*bookstack.InstancePage {
+
+	pages := append([]*bookstack.InstancePage{}, book.Pages...)
+
+	for _, c := range book.Chapters {
+		pages = append(pages, c.Pages...)
+	}
+
+	return pages
+}
+
+// writePage fetches a page, with its attachments and images, and writes it
+// to target.
+func (m *Mirror) writePage(ctx context.Context, l *linker, page *bookstack.InstancePage, target string) (pageState, error) {
+
+	detail, err := m.client.GetPage(ctx, page.Page.ID)
+	if err != nil {
+		return pageState{}, err
+	}
+
+	body := detail.HTML
+
+	if m.format == Markdown {
+
+		export, err := m.client.ExportPage(ctx, page.Page.ID, bookstack.ExportMarkdown)
+		if err != nil {
+			return pageState{}, err
+		}
+
+		buf := bytes.NewBuffer(nil)
+
+		if _, err := export.CopyTo(buf, nil); err != nil {
+			return pageState{}, err
+		}
+
+		body = buf.String()
+	}
+
+	written := pageState{Path: target, UpdatedAt: detail.UpdatedAt, Assets: []string{}}
+	assets := strings.TrimSuffix(target, m.format.ext()) + "_files"
+
+	front := FrontMatter{
+		ID:        detail.ID,
+		Type:      bookstack.ContentPage,
+		Name:      detail.Name,
+		Slug:      detail.Slug,
+		UpdatedAt: detail.UpdatedAt,
+	}
+
+	for _, t := range detail.Tags {
+		front.Tags = append(front.Tags, Tag{Name: t.Name, Value: t.Value})
+	}
+
+	attachments, err := m.client.AllAttachments(ctx, &bookstack.QueryParams{FilterField: "uploaded_to", FilterValue: strconv.Itoa(page.Page.ID)})
+	if err != nil {
+		return pageState{}, err
+	}
+
+	files := map[string]string{}
+
+	for _, a := range attachments {
+
+		if a.External {
+
+			link, err := m.client.DownloadAttachment(ctx, a.ID, io.Discard)
+			if err != nil {
+				return pageState{}, err
+			}
+
+			if external, ok := link.(bookstack.AttachmentLink); ok {
+				front.Links = append(front.Links, Link{Name: external.Name, URL: external.URL})
+			}
+
+			continue
+		}
+
+		file := path.Join(assets, "attachment-"+strconv.Itoa(a.ID)+"-"+safeName(a.Name, a.Extension))
+
+		if err := m.download(file, func(w io.Writer) error {
+			_, err := m.client.DownloadAttachment(ctx, a.ID, w)
+			return err
+		}); err != nil {
+			return pageState{}, err
+		}
+
+		files[fmt.Sprintf("/attachments/%d", a.ID)] = file
+		written.Assets = append(written.Assets, file)
+	}
+
+	for n, image := range l.images(body) {
+
+		file := path.Join(assets, path.Base(image))
+
+		// Images uploaded in different months can share a name.
+		for _, used := range written.Assets {
+			if used == file {
+				file = path.Join(assets, strconv.Itoa(n)+"-"+path.Base(image))
+			}
+		}
+
+		if err := m.download(file, func(w io.Writer) error {
+			_, err := m.client.DownloadImage(ctx, l.base+image, w)
+			return err
+		}); err != nil {
+			return pageState{}, err
+		}
+
+		files[image] = file
+		written.Assets = append(written.Assets, file)
+	}
+
+	body, written.Links = l.rewrite(target, body, files)
+
+	if err := m.writeFile(target, front, body); err != nil {
+		return pageState{}, err
+	}
+
+	return written, nil
+}
+
+// writeIndexes writes the index of every book and chapter, and a file for
+// every shelf, and returns their paths.
+func (m *Mirror) writeIndexes(l *linker, inst *bookstack.Instance) ([]string, error) {
+
+	written := []string{}
+
+	write := func(target string, front FrontMatter, description string, children []entry) error {
+
+		if err := m.writeFile(target, front, m.index(target, front.Name, description, children)); err != nil {
+			return err
+		}
+
+		written = append(written, target)
+
+		return nil
+	}
+
+	for _, book := range inst.Books {
+
+		children := []entry{}
+
+		for _, c := range book.Chapters {
+			children = append(children, entry{c.Chapter.Name, l.chapters[c.Chapter.ID], c.Chapter.Priority})
+		}
+
+		for _, p := range book.Pages {
+			children = append(children, entry{p.Page.Name, l.pages[p.Page.ID], p.Page.Priority})
+		}
+
+		sort.SliceStable(children, func(i, j int) bool {
+			return children[i].priority < children[j].priority
+		})
+
+		front := FrontMatter{ID: book.Book.ID, Type: bookstack.ContentBook, Name: book.Book.Name, Slug: book.Book.Slug, UpdatedAt: book.Book.UpdatedAt}
+
+		if err := write(l.books[book.Book.ID], front, book.Book.Description, children); err != nil {
+			return nil, err
+		}
+
+		for _, c := range book.Chapters {
+
+			children := []entry{}
+
+			for _, p := range c.Pages {
+				children = append(children, entry{p.Page.Name, l.pages[p.Page.ID], p.Page.Priority})
+			}
+
+			front := FrontMatter{ID: c.Chapter.ID, Type: bookstack.ContentChapter, Name: c.Chapter.Name, Slug: c.Chapter.Slug, UpdatedAt: c.Chapter.UpdatedAt}
+
+			if err := write(l.chapters[c.Chapter.ID], front, c.Chapter.Description, children); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, shelf := range inst.Shelves {
+
+		children := []entry{}
+
+		for _, b := range shelf.Books {
+			children = append(children, entry{b.Book.Name, l.books[b.Book.ID], 0})
+		}
+
+		front := FrontMatter{ID: shelf.Shelf.ID, Type: bookstack.ContentShelf, Name: shelf.Shelf.Name, Slug: shelf.Shelf.Slug, UpdatedAt: shelf.Shelf.UpdatedAt}
+
+		if err := write(l.shelves[shelf.Shelf.ID], front, shelf.Shelf.Description, children); err != nil {
+			return nil, err
+		}
+	}
+
+	return written, nil
+}
+
+type entry struct {
+	name     string
+	path     string
+	priority int
+}
+
+// index returns the body of an index file at target, linking to children.
+func (m *Mirror) index(target, name, description string, children []entry) string {
+
+	b := &strings.Builder{}
+
+	if m.format == HTML {
+
+		fmt.Fprintf(b, "<h1>%s</h1>\n", html.EscapeString(name))
+
+		if description != "" {
+			fmt.Fprintf(b, "<p>%s</p>\n", html.EscapeString(description))
+		}
+
+		b.WriteString("<ul>\n")
+
+		for _, c := range children {
+			fmt.Fprintf(b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(relative(target, c.path)), html.EscapeString(c.name))
+		}
+
+		b.WriteString("</ul>\n")
+
+		return b.String()
+	}
+
+	fmt.Fprintf(b, "# %s\n\n", name)
+
+	if description != "" {
+		fmt.Fprintf(b, "%s\n\n", description)
+	}
+
+	for _, c := range children {
+		fmt.Fprintf(b, "- [%s](%s)\n", c.name, relative(target, c.path))
+	}
+
+	return b.String()
+}
+
+func (m *Mirror) writeFile(target string, front FrontMatter, body string) error {
+
+	content, err := localfs.Render(front, strings.TrimLeft(body, "\n"))
+	if err != nil {
+		return err
+	}
+
+	return localfs.WriteFile(m.dir, target, content)
+}
+
+// download writes the file at target with fetch, removing it if fetch fails.
+func (m *Mirror) download(target string, fetch func(io.Writer) error) error {
+
+	file := filepath.Join(m.dir, filepath.FromSlash(target))
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := fetch(f); err != nil {
+		f.Close()
+		os.Remove(file)
+		return err
+	}
+
+	return f.Close()
+}
+
+func (m *Mirror) exists(target string) bool {
+	_, err := os.Stat(filepath.Join(m.dir, filepath.FromSlash(target)))
+	return err == nil
+}
+
+// removeStale removes the files of old that are not in current, and returns
+// how many were removed.
+func (m *Mirror) removeStale(old, current pageState) int {
+
+	keep := map[string]bool{current.Path: true}
+	for _, a := range current.Assets {
+		keep[a] = true
+	}
+
+	removed := 0
+
+	for _, f := range append([]string{old.Path}, old.Assets...) {
+		if !keep[f] && localfs.Remove(m.dir, f) {
+			removed++
+		}
+	}
+
+	return removed
+}
+
+func (m *Mirror) loadState() (*state, error) {
+
+	s := &state{Pages: map[int]pageState{}}
+
+	if err := localfs.LoadState(m.dir, StateFile, s); err != nil {
+		return nil, fmt.Errorf("mirror: reading %s: %w", StateFile, err)
+	}
+
+	if s.Pages == nil {
+		s.Pages = map[int]pageState{}
+	}
+
+	return s, nil
+}
+
+func (m *Mirror) saveState(s *state) error {
+	return localfs.SaveState(m.dir, StateFile, s)
+}
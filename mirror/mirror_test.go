@@ -0,0 +1,205 @@
+package mirror_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/hcarriz/go-bookstack/mirror"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func readFile(t *testing.T, dir, name string) (mirror.FrontMatter, string) {
+
+	raw, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	require.NoError(t, err)
+
+	parts := strings.SplitN(string(raw), "---\n", 3)
+	require.Len(t, parts, 3)
+
+	front := mirror.FrontMatter{}
+	require.NoError(t, yaml.Unmarshal([]byte(parts[1]), &front))
+
+	return front, strings.TrimPrefix(parts[2], "\n")
+}
+
+func TestMirror(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	dir := t.TempDir()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Guide", Description: "How things work"})
+	check.NoError(err)
+
+	_, err = bk.CreateShelf(ctx, bookstack.ShelfParams{Name: "Docs", Books: []int{book.ID}})
+	check.NoError(err)
+
+	chapter, err := bk.CreateChapter(ctx, bookstack.ChapterParams{BookID: book.ID, Name: "Basics"})
+	check.NoError(err)
+
+	other, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Other", Markdown: "Other things."})
+	check.NoError(err)
+
+	start, err := bk.CreatePage(ctx, bookstack.PageParams{ChapterID: chapter.ID, Name: "Start", Markdown: "Starting.", Tags: []bookstack.TagParams{{Name: "level", Value: "easy"}}})
+	check.NoError(err)
+
+	image, err := bk.CreateImage(ctx, bookstack.ImageParams{
+		Type:        bookstack.ImageGallery,
+		UploadedTo:  start.ID,
+		ImageUpload: &bookstack.Upload{Name: "diagram.png", Reader: strings.NewReader("diagram")},
+	})
+	check.NoError(err)
+
+	attachment, err := bk.CreateAttachment(ctx, bookstack.AttachmentParams{
+		Name:       "Notes",
+		UploadedTo: start.ID,
+		FileUpload: &bookstack.Upload{Name: "notes.txt", Reader: strings.NewReader("some notes")},
+	})
+	check.NoError(err)
+
+	_, err = bk.CreateAttachment(ctx, bookstack.AttachmentParams{Name: "Site", UploadedTo: start.ID, Link: "https://example.com"})
+	check.NoError(err)
+
+	markdown := strings.Join([]string{
+		fmt.Sprintf("See [other](%s/books/guide/page/other#top).", srv.URL),
+		fmt.Sprintf("![diagram](%s)", image.URL),
+		fmt.Sprintf("[notes](%s/attachments/%d)", srv.URL, attachment.ID),
+		"[outside](https://example.com/books/guide)",
+	}, "\n\n")
+
+	_, err = bk.UpdatePage(ctx, start.ID, bookstack.PageParams{Markdown: markdown})
+	check.NoError(err)
+
+	m := mirror.New(bk, dir)
+
+	result, err := m.Run(ctx)
+	check.NoError(err)
+	check.ElementsMatch([]int{start.ID, other.ID}, result.Fetched)
+	check.Zero(result.Skipped)
+
+	front, body := readFile(t, dir, "books/guide/basics/start.md")
+	check.Equal(start.ID, front.ID)
+	check.Equal(bookstack.ContentPage, front.Type)
+	check.Equal("start", front.Slug)
+	check.Equal([]mirror.Tag{{Name: "level", Value: "easy"}}, front.Tags)
+	check.Equal([]mirror.Link{{Name: "Site", URL: "https://example.com"}}, front.Links)
+	check.False(front.UpdatedAt.IsZero())
+
+	check.Contains(body, "[other](../other.md#top)")
+	check.Contains(body, "![diagram](start_files/diagram.png)")
+	check.Contains(body, fmt.Sprintf("[notes](start_files/attachment-%d-Notes.txt)", attachment.ID))
+	check.Contains(body, "[outside](https://example.com/books/guide)")
+
+	data, err := os.ReadFile(filepath.Join(dir, "books/guide/basics/start_files/diagram.png"))
+	check.NoError(err)
+	check.Equal("diagram", string(data))
+
+	data, err = os.ReadFile(filepath.Join(dir, fmt.Sprintf("books/guide/basics/start_files/attachment-%d-Notes.txt", attachment.ID)))
+	check.NoError(err)
+	check.Equal("some notes", string(data))
+
+	front, body = readFile(t, dir, "books/guide/_index.md")
+	check.Equal(bookstack.ContentBook, front.Type)
+	check.Contains(body, "How things work")
+	check.Contains(body, "- [Basics](basics/_index.md)")
+	check.Contains(body, "- [Other](other.md)")
+
+	_, body = readFile(t, dir, "books/guide/basics/_index.md")
+	check.Contains(body, "- [Start](start.md)")
+
+	_, body = readFile(t, dir, "shelves/docs.md")
+	check.Contains(body, "- [Guide](../books/guide/_index.md)")
+
+	result, err = m.Run(ctx)
+	check.NoError(err)
+	check.Empty(result.Fetched)
+	check.Equal(2, result.Skipped)
+
+	_, err = bk.UpdatePage(ctx, other.ID, bookstack.PageParams{Markdown: "Changed."})
+	check.NoError(err)
+
+	result, err = m.Run(ctx)
+	check.NoError(err)
+	check.Equal([]int{other.ID}, result.Fetched)
+
+	_, body = readFile(t, dir, "books/guide/other.md")
+	check.Contains(body, "Changed.")
+
+	// Moving a page rewrites the pages that link to it.
+	_, err = bk.UpdatePage(ctx, other.ID, bookstack.PageParams{ChapterID: chapter.ID})
+	check.NoError(err)
+
+	result, err = m.Run(ctx)
+	check.NoError(err)
+	check.ElementsMatch([]int{start.ID, other.ID}, result.Fetched)
+	check.NoFileExists(filepath.Join(dir, "books/guide/other.md"))
+
+	_, body = readFile(t, dir, "books/guide/basics/start.md")
+	check.Contains(body, "[other](other.md#top)")
+
+	result, err = m.Run(ctx)
+	check.NoError(err)
+	check.Empty(result.Fetched)
+
+	_, err = bk.DeletePage(ctx, start.ID)
+	check.NoError(err)
+
+	result, err = m.Run(ctx)
+	check.NoError(err)
+	check.Equal(3, result.Removed)
+	check.NoFileExists(filepath.Join(dir, "books/guide/basics/start.md"))
+	check.NoDirExists(filepath.Join(dir, "books/guide/basics/start_files"))
+	check.FileExists(filepath.Join(dir, "books/guide/basics/_index.md"))
+}
+
+func TestMirrorHTML(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	dir := t.TempDir()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Guide"})
+	check.NoError(err)
+
+	other, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Other", HTML: "<p>Other</p>"})
+	check.NoError(err)
+
+	_, err = bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Start", HTML: fmt.Sprintf(`<p><a href="%s/link/%d">other</a></p>`, srv.URL, other.ID)})
+	check.NoError(err)
+
+	_, err = mirror.New(bk, dir, mirror.SetFormat(mirror.HTML)).Run(ctx)
+	check.NoError(err)
+
+	_, body := readFile(t, dir, "books/guide/start.html")
+	check.Equal(`<p><a href="other.html">other</a></p>`, body)
+
+	_, body = readFile(t, dir, "books/guide/_index.html")
+	check.Contains(body, `<li><a href="start.html">Start</a></li>`)
+
+	result, err := mirror.New(bk, dir).Run(ctx)
+	check.NoError(err)
+	check.Len(result.Fetched, 2)
+	check.Equal(3, result.Removed)
+	check.FileExists(filepath.Join(dir, "books/guide/start.md"))
+}
@@ -13,15 +13,23 @@ type Response struct {
 	Data  json.RawMessage `json:"data,omitempty"`
 	Total int             `json:"total,omitempty"`
 	Err   struct {
-		Code    int    `json:"code,omitempty"`
-		Message string `json:"message,omitempty"`
+		Code       int                 `json:"code,omitempty"`
+		Message    string              `json:"message,omitempty"`
+		Validation map[string][]string `json:"validation,omitempty"`
 	} `json:"error,omitempty"`
 }
 
+// Error returns the error in the response as an *APIError, or nil if there
+// is none.
 func (r Response) Error() error {
 	switch {
 	case r.Err.Code != 0 || r.Err.Message != "":
-		return fmt.Errorf("%d %s", r.Err.Code, r.Err.Message)
+		return &APIError{
+			StatusCode: r.Err.Code,
+			Code:       r.Err.Code,
+			Message:    r.Err.Message,
+			Validation: r.Err.Validation,
+		}
 	default:
 		return nil
 	}
@@ -34,6 +42,38 @@ type QueryParams struct {
 	SortDescending bool
 	FilterField    string
 	FilterValue    string
+	// Filters are applied in addition to FilterField and FilterValue.
+	Filters []Filter
+}
+
+// FilterOperator compares a field to the value of a Filter.
+type FilterOperator string
+
+const (
+	FilterEqual          FilterOperator = "eq"
+	FilterNotEqual       FilterOperator = "ne"
+	FilterGreaterThan    FilterOperator = "gt"
+	FilterLessThan       FilterOperator = "lt"
+	FilterGreaterOrEqual FilterOperator = "gte"
+	FilterLessOrEqual    FilterOperator = "lte"
+	FilterLike           FilterOperator = "like"
+)
+
+// Filter is a condition on a field of a list endpoint. An empty Operator
+// tests for equality.
+type Filter struct {
+	Field    string
+	Operator FilterOperator
+	Value    string
+}
+
+func (f Filter) key() string {
+
+	if f.Operator == "" {
+		return fmt.Sprintf("filter[%s]", f.Field)
+	}
+
+	return fmt.Sprintf("filter[%s:%s]", f.Field, f.Operator)
 }
 
 func (q *QueryParams) String(l string) string {
@@ -66,6 +106,10 @@ func (q *QueryParams) String(l string) string {
 		u.Add(fmt.Sprintf("filter[%s]", q.FilterField), q.FilterValue)
 	}
 
+	for _, f := range q.Filters {
+		u.Add(f.key(), f.Value)
+	}
+
 	return fmt.Sprintf("%s?%s", l, u.Encode())
 
 }
@@ -82,6 +126,24 @@ type TagParams struct {
 	Value string `json:"value,omitempty"`
 }
 
+// tagFields returns tags as the fields of a multipart form, leaving out empty
+// values as they are left out of JSON.
+func tagFields(tags []TagParams) []formField {
+
+	fields := []formField{}
+
+	for n, t := range tags {
+
+		fields = append(fields, formField{fmt.Sprintf("tags[%d][name]", n), t.Name})
+
+		if t.Value != "" {
+			fields = append(fields, formField{fmt.Sprintf("tags[%d][value]", n), t.Value})
+		}
+	}
+
+	return fields
+}
+
 type CreatedBy struct {
 	ID   int    `json:"id,omitempty"`
 	Name string `json:"name,omitempty"`
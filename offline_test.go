@@ -0,0 +1,158 @@
+package bookstack_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffline(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	img := "./test_data/upload.png"
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	t.Run("users", func(t *testing.T) {
+
+		users, err := bk.ListUsers(ctx, nil)
+		check.NoError(err)
+		check.Len(users, 2)
+
+		created, err := bk.CreateUser(ctx, bookstack.UserParams{Name: "Jane Doe", Email: "jane@example.com", Roles: []int{3}})
+		check.NoError(err)
+		check.Equal("jane@example.com", created.Email)
+		check.Len(created.Roles, 1)
+
+		updated, err := bk.UpdateUser(ctx, created.ID, bookstack.UserParams{Email: "doe@example.com"})
+		check.NoError(err)
+		check.Equal("doe@example.com", updated.Email)
+
+		users, err = bk.ListUsers(ctx, &bookstack.QueryParams{Count: 1})
+		check.NoError(err)
+		check.Len(users, 1)
+
+		ok, err := bk.DeleteUser(ctx, created.ID, nil)
+		check.NoError(err)
+		check.True(ok)
+
+		_, err = bk.GetUser(ctx, created.ID)
+		check.Error(err)
+	})
+
+	t.Run("content", func(t *testing.T) {
+
+		book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Handbook", Image: img})
+		check.NoError(err)
+		check.Equal("handbook", book.Slug)
+
+		detailed, err := bk.GetBook(ctx, book.ID)
+		check.NoError(err)
+		check.NotEmpty(detailed.Cover.URL)
+
+		chapter, err := bk.CreateChapter(ctx, bookstack.ChapterParams{
+			BookID: book.ID,
+			Name:   "Onboarding",
+			Tags:   []bookstack.TagParams{{Name: "status", Value: "draft"}},
+		})
+		check.NoError(err)
+
+		page, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Welcome", HTML: "<p>Hello there</p>"})
+		check.NoError(err)
+		check.Equal(book.ID, page.BookID)
+
+		pages, err := bk.ListPages(ctx, &bookstack.QueryParams{FilterField: "book_id", FilterValue: "1"})
+		check.NoError(err)
+		check.Len(pages, 1)
+
+		detailedChapter, err := bk.GetChapter(ctx, chapter.ID)
+		check.NoError(err)
+		check.Len(detailedChapter.Tags, 1)
+
+		plain, err := bk.ExportBookPlaintext(ctx, book.ID)
+		check.NoError(err)
+
+		raw, err := ioutil.ReadAll(plain)
+		check.NoError(err)
+		check.Contains(string(raw), "Hello there")
+
+		attachment, err := bk.CreateAttachment(ctx, bookstack.AttachmentParams{Name: "Logo", UploadedTo: page.ID, File: img})
+		check.NoError(err)
+		check.Equal("png", attachment.Extension)
+
+		shelf, err := bk.CreateShelf(ctx, bookstack.ShelfParams{Name: "Company", Books: []int{book.ID}})
+		check.NoError(err)
+
+		detailedShelf, err := bk.GetShelf(ctx, shelf.ID)
+		check.NoError(err)
+		check.Len(detailedShelf.Books, 1)
+
+		results, err := bk.Search(ctx, bookstack.SearchParams{Query: "hello", Type: []bookstack.ContentType{bookstack.ContentPage}})
+		check.NoError(err)
+		check.Len(results, 1)
+		check.Equal(page.ID, results[0].ID)
+
+		ok, err := bk.DeleteBook(ctx, book.ID)
+		check.NoError(err)
+		check.True(ok)
+
+		items, err := bk.ListRecycleBinItems(ctx)
+		check.NoError(err)
+		check.Len(items, 1)
+
+		recycled, ok := items[0].Book()
+		check.True(ok)
+		check.Equal(1, recycled.PagesCount)
+		check.Equal(1, recycled.ChaptersCount)
+
+		count, err := bk.RestoreRecyleBinItem(ctx, items[0].ID)
+		check.NoError(err)
+		check.Equal(3, count)
+
+		_, err = bk.GetPage(ctx, page.ID)
+		check.NoError(err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+
+		_, err := bk.GetBook(ctx, 999)
+		check.Error(err)
+
+		_, err = bk.CreateBook(ctx, bookstack.BookParams{})
+		check.Error(err)
+
+		_, err = bookstack.New(bookstack.SetURL(srv.URL)).ListBooks(ctx, nil)
+		check.Error(err)
+	})
+
+}
+
+func TestServerSeeding(t *testing.T) {
+
+	check := require.New(t)
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	book := srv.AddBook(bookstack.BookDetailed{Name: "Seeded Book"})
+	srv.AddPage(bookstack.PageDetailed{BookID: book.ID, Name: "Seeded Page", Markdown: "# Title\n\nBody"})
+
+	pages, err := srv.Client().ListPages(context.Background(), nil)
+	check.NoError(err)
+	check.Len(pages, 1)
+	check.Equal("seeded-page", pages[0].Slug)
+
+	page, ok := srv.Page(pages[0].ID)
+	check.True(ok)
+	check.Contains(page.HTML, "<h1>Title</h1>")
+}
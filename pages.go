@@ -11,8 +11,10 @@ import (
 )
 
 type Page struct {
-	ID            int       `json:"id,omitempty"`
-	BookID        int       `json:"book_id,omitempty"`
+	ID     int `json:"id,omitempty"`
+	BookID int `json:"book_id,omitempty"`
+	// Deprecated: PageID is never set by BookStack. Use ID, or ChapterID for
+	// the chapter of the page.
 	PageID        int       `json:"page_id,omitempty"`
 	Name          string    `json:"name,omitempty"`
 	Slug          string    `json:"slug,omitempty"`
@@ -24,6 +26,7 @@ type Page struct {
 	Draft         bool      `json:"draft,omitempty"`
 	RevisionCount int       `json:"revision_count,omitempty"`
 	Template      bool      `json:"template,omitempty"`
+	ChapterID     int       `json:"chapter_id,omitempty"`
 }
 
 type PageDetailed struct {
@@ -48,11 +51,14 @@ type PageDetailed struct {
 
 type PageParams struct {
 	BookID    int         `json:"book_id,omitempty"`
-	ChapterID int         `json:"chapterID,omitempty"`
+	ChapterID int         `json:"chapter_id,omitempty"`
 	Name      string      `json:"name,omitempty"`
 	HTML      string      `json:"html,omitempty"`
 	Markdown  string      `json:"markdown,omitempty"`
 	Tags      []TagParams `json:"tags,omitempty"`
+	// Priority sets the position within the book or chapter. Nil leaves it
+	// as it is.
+	Priority *int `json:"priority,omitempty"`
 }
 
 func (bp PageParams) Form() (string, io.Reader, error) {
@@ -77,6 +83,18 @@ func (b *Bookstack) ListPages(ctx context.Context, params *QueryParams) ([]Page,
 	return ParseMultiple[[]Page](resp)
 }
 
+// ListPagesResult will return a page of the pages that match the given params,
+// along with the total and the pagination applied.
+func (b *Bookstack) ListPagesResult(ctx context.Context, params *QueryParams) (ListResult[Page], error) {
+	return List[Page](ctx, b, "/pages", params)
+}
+
+// AllPages will return every page that matches the given params, following
+// pagination. The Count of params sets the page size.
+func (b *Bookstack) AllPages(ctx context.Context, params *QueryParams) ([]Page, error) {
+	return Paginate[Page](ctx, b, "/pages", params).All()
+}
+
 // GetPage will return a single page that matches id.
 func (b *Bookstack) GetPage(ctx context.Context, id int) (PageDetailed, error) {
 
@@ -0,0 +1,225 @@
+package bookstack
+
+import "context"
+
+// PaginateOption configures a Paginator.
+type PaginateOption func(*paginateConfig)
+
+type paginateConfig struct {
+	prefetch int
+}
+
+// Prefetch fetches up to n pages ahead of the one being read, concurrently.
+// The number of pages is taken from the total reported with the first page.
+func Prefetch(n int) PaginateOption {
+	return func(c *paginateConfig) {
+		c.prefetch = n
+	}
+}
+
+// maxCount is the largest page size BookStack returns from list endpoints.
+// Larger counts are lowered to it.
+const maxCount = 500
+
+// Paginator iterates over every item of a list endpoint, fetching pages
+// lazily as items are read. It is used like bufio.Scanner:
+//
+//	p := Paginate[Book](ctx, b, "/books", nil)
+//	defer p.Close()
+//
+//	for p.Next() {
+//		book := p.Item()
+//	}
+//
+//	if err := p.Err(); err != nil {
+//		...
+//	}
+type Paginator[T any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	b      *Bookstack
+	path   string
+	params QueryParams
+	config paginateConfig
+
+	buf    []T
+	item   T
+	offset int
+	total  int
+	done   bool
+	err    error
+	queue  chan chan listPage[T]
+}
+
+type listPage[T any] struct {
+	items []T
+	total int
+	err   error
+}
+
+// Paginate returns a paginator over the list endpoint at path, such as
+// "/books". The Count of params sets the page size, up to 500, and Offset
+// the first item to return.
+func Paginate[T any](ctx context.Context, b *Bookstack, path string, params *QueryParams, opts ...PaginateOption) *Paginator[T] {
+
+	p := &Paginator[T]{
+		b:    b,
+		path: path,
+	}
+
+	p.ctx, p.cancel = context.WithCancel(ctx)
+
+	if params != nil {
+		p.params = *params
+	}
+
+	if p.params.Count <= 0 {
+		p.params.Count = defaultCount
+	}
+
+	if p.params.Count > maxCount {
+		p.params.Count = maxCount
+	}
+
+	p.offset = p.params.Offset
+
+	for _, opt := range opts {
+		opt(&p.config)
+	}
+
+	return p
+}
+
+// Next advances to the next item, fetching a page if needed. It returns
+// false when there are no more items or an error occurred.
+func (p *Paginator[T]) Next() bool {
+
+	for len(p.buf) == 0 {
+
+		if p.done || p.err != nil {
+			return false
+		}
+
+		page := p.nextPage()
+
+		if page.err != nil {
+			p.err = page.err
+			p.cancel()
+			return false
+		}
+
+		p.total = page.total
+		p.buf = page.items
+
+		if len(page.items) == 0 || p.offset >= p.total {
+			p.done = true
+			p.cancel()
+		}
+	}
+
+	p.item, p.buf = p.buf[0], p.buf[1:]
+
+	return true
+}
+
+// Item returns the current item.
+func (p *Paginator[T]) Item() T {
+	return p.item
+}
+
+// Err returns the first error that stopped the iteration.
+func (p *Paginator[T]) Err() error {
+	return p.err
+}
+
+// Total returns the total number of items reported by the server. It is
+// zero until the first call to Next.
+func (p *Paginator[T]) Total() int {
+	return p.total
+}
+
+// Close stops any pages being prefetched. It only needs to be called when
+// iteration is abandoned early.
+func (p *Paginator[T]) Close() {
+	p.cancel()
+}
+
+// All reads every remaining item.
+func (p *Paginator[T]) All() ([]T, error) {
+
+	defer p.Close()
+
+	result := []T{}
+
+	for p.Next() {
+		result = append(result, p.Item())
+	}
+
+	return result, p.Err()
+}
+
+func (p *Paginator[T]) nextPage() listPage[T] {
+
+	if p.queue != nil {
+
+		ch, ok := <-p.queue
+		if !ok {
+			return listPage[T]{total: p.total}
+		}
+
+		page := <-ch
+		p.offset += p.params.Count
+
+		return page
+	}
+
+	page := p.fetch(p.offset)
+	p.offset += p.params.Count
+
+	if page.err == nil && p.config.prefetch > 0 {
+		p.prefetch(page.total)
+	}
+
+	return page
+}
+
+// prefetch starts fetching every remaining page up to total, keeping at most
+// the configured number of pages ahead of the reader.
+func (p *Paginator[T]) prefetch(total int) {
+
+	p.queue = make(chan chan listPage[T], p.config.prefetch)
+
+	go func(from int) {
+
+		defer close(p.queue)
+
+		for offset := from; offset < total; offset += p.params.Count {
+
+			ch := make(chan listPage[T], 1)
+
+			select {
+			case p.queue <- ch:
+			case <-p.ctx.Done():
+				return
+			}
+
+			go func(offset int) {
+				ch <- p.fetch(offset)
+			}(offset)
+		}
+
+	}(p.offset)
+}
+
+func (p *Paginator[T]) fetch(offset int) listPage[T] {
+
+	params := p.params
+	params.Offset = offset
+
+	result, err := List[T](p.ctx, p.b, p.path, &params)
+	if err != nil {
+		return listPage[T]{err: err}
+	}
+
+	return listPage[T]{items: result.Items, total: result.Total}
+}
@@ -0,0 +1,79 @@
+package bookstack_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	for i := 0; i < 250; i++ {
+		srv.AddBook(bookstack.BookDetailed{Name: fmt.Sprintf("Book %03d", i)})
+	}
+
+	bk := srv.Client()
+
+	books, err := bk.AllBooks(ctx, nil)
+	check.NoError(err)
+	check.Len(books, 250)
+
+	for _, prefetch := range []int{0, 1, 4} {
+
+		p := bookstack.Paginate[bookstack.Book](ctx, bk, "/books", &bookstack.QueryParams{Count: 30, SortField: "name", SortDescending: true}, bookstack.Prefetch(prefetch))
+
+		names := []string{}
+		for p.Next() {
+			names = append(names, p.Item().Name)
+		}
+
+		check.NoError(p.Err())
+		check.Equal(250, p.Total())
+		check.Len(names, 250)
+		check.Equal("Book 249", names[0])
+		check.Equal("Book 000", names[249])
+	}
+
+	p := bookstack.Paginate[bookstack.Book](ctx, bk, "/books", &bookstack.QueryParams{Count: 10, Offset: 245}, bookstack.Prefetch(2))
+	rest, err := p.All()
+	check.NoError(err)
+	check.Len(rest, 5)
+
+	p = bookstack.Paginate[bookstack.Book](ctx, bk, "/books", &bookstack.QueryParams{Count: 10}, bookstack.Prefetch(3))
+	check.True(p.Next())
+	p.Close()
+
+	for i := 250; i < 1200; i++ {
+		srv.AddBook(bookstack.BookDetailed{Name: fmt.Sprintf("Book %04d", i)})
+	}
+
+	// Counts above what the server returns are lowered to it.
+	for _, prefetch := range []int{0, 2} {
+
+		all, err := bookstack.Paginate[bookstack.Book](ctx, bk, "/books", &bookstack.QueryParams{Count: 1000}, bookstack.Prefetch(prefetch)).All()
+		check.NoError(err)
+		check.Len(all, 1200)
+	}
+
+	books, err = bk.AllBooks(ctx, &bookstack.QueryParams{Count: 1000})
+	check.NoError(err)
+	check.Len(books, 1200)
+
+	filtered, err := bk.AllBooks(ctx, &bookstack.QueryParams{FilterField: "name", FilterValue: "Book 007"})
+	check.NoError(err)
+	check.Len(filtered, 1)
+
+	_, err = bookstack.Paginate[bookstack.Book](ctx, bk, "/missing", nil).All()
+	check.ErrorIs(err, bookstack.ErrNotFound)
+}
@@ -0,0 +1,874 @@
+// Package publish pushes a directory of Markdown files into BookStack. The
+// layout of the directory is:
+//
+//	{book}/_index.md
+//	{book}/{page}.md
+//	{book}/{chapter}/_index.md
+//	{book}/{chapter}/{page}.md
+//
+// Every directory in the root is a book, and every directory in a book that
+// holds Markdown files is a chapter. Other directories, such as ones holding
+// images, are left alone. The _index.md files are optional, and give the
+// name, tags and description of their book or chapter. Every other Markdown
+// file is a page. Files may start with YAML front matter:
+//
+//	---
+//	id: 12
+//	name: Restarting the server
+//	priority: 3
+//	tags:
+//	  - name: team
+//	    value: ops
+//	---
+//
+// Books, chapters and pages are matched to the ones on the instance by id,
+// when their front matter has one, or else by the slug of their name. Only
+// what the files give is published: a file without a name or tags leaves the
+// name or tags on the instance as they are. Images that pages link to by a
+// relative path are uploaded to the gallery of the page, once, and linked to
+// by their URL.
+package publish
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/internal/localfs"
+)
+
+// IndexFile is the name of the file that describes the book or chapter of
+// its directory.
+const IndexFile = "_index.md"
+
+// FrontMatter is the YAML header of a file.
+type FrontMatter struct {
+	// ID is the id of the item on the instance. Items without one are
+	// matched by the slug of their name.
+	ID int `yaml:"id,omitempty"`
+	// Name defaults to the name of the item on the instance, or for new
+	// items to the name of the file or directory.
+	Name string `yaml:"name,omitempty"`
+	// Priority is the position within the book or chapter. Items without
+	// one keep their position.
+	Priority *int  `yaml:"priority,omitempty"`
+	Tags     []Tag `yaml:"tags,omitempty"`
+}
+
+type Tag struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value,omitempty"`
+}
+
+// Action is what an Operation does to an item.
+type Action string
+
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+// Operation is a change to the instance made by publishing.
+type Operation struct {
+	Action Action
+	Type   bookstack.ContentType
+	// ID is zero for items that are yet to be created.
+	ID   int
+	Name string
+	// Path is the file or directory the item is read from, relative to the
+	// published directory. It is empty for deletes.
+	Path string
+	// Images are the paths of the images uploaded for a page.
+	Images []string
+
+	apply func(ctx context.Context) error
+}
+
+func (o Operation) String() string {
+
+	var s strings.Builder
+
+	s.WriteString(string(o.Action) + " " + string(o.Type))
+
+	if o.ID != 0 {
+		fmt.Fprintf(&s, " %d", o.ID)
+	}
+
+	fmt.Fprintf(&s, " %q", o.Name)
+
+	if o.Path != "" {
+		s.WriteString(" from " + o.Path)
+	}
+
+	if len(o.Images) > 0 {
+		s.WriteString(", uploading " + strings.Join(o.Images, ", "))
+	}
+
+	return s.String()
+}
+
+// Plan is the operations of a publish, in the order they are made.
+type Plan []Operation
+
+// WriteTo writes the operations of the plan to w, one per line.
+func (p Plan) WriteTo(w io.Writer) (int64, error) {
+
+	var total int64
+
+	for _, op := range p {
+
+		n, err := fmt.Fprintln(w, op)
+		total += int64(n)
+
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// Option configures a Publisher.
+type Option func(*Publisher)
+
+// DryRun writes the plan to w instead of making any changes.
+func DryRun(w io.Writer) Option {
+	return func(p *Publisher) {
+		p.dryRun = w
+	}
+}
+
+// Prune deletes the chapters and pages of the published books that have no
+// file in the directory. Books themselves are never deleted.
+func Prune() Option {
+	return func(p *Publisher) {
+		p.prune = true
+	}
+}
+
+// Publisher pushes a directory to a BookStack instance.
+type Publisher struct {
+	client *bookstack.Bookstack
+	dir    string
+	dryRun io.Writer
+	prune  bool
+}
+
+// New returns a publisher of dir to the instance of client.
+func New(client *bookstack.Bookstack, dir string, opts ...Option) *Publisher {
+
+	p := &Publisher{
+		client: client,
+		dir:    dir,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Run will plan the operations that bring the instance in line with the
+// directory, and make them in order. Items that are already up to date have
+// no operation. The plan is returned even when an operation fails, and the
+// error names the operation.
+func (p *Publisher) Run(ctx context.Context) (Plan, error) {
+
+	books, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+
+	inst, err := p.client.Crawl(ctx, bookstack.CrawlFailFast(), bookstack.CrawlExclude(func(item bookstack.CrawlItem) bool {
+		return item.Type == bookstack.ContentShelf
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	pl := &planner{publisher: p, inst: inst, claimed: map[string]string{}}
+
+	for _, book := range books {
+		if err := pl.book(ctx, book); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.prune {
+		pl.pruneBooks(books)
+	}
+
+	if p.dryRun != nil {
+		_, err := pl.plan.WriteTo(p.dryRun)
+		return pl.plan, err
+	}
+
+	for _, op := range pl.plan {
+		if err := op.apply(ctx); err != nil {
+			return pl.plan, fmt.Errorf("publish: %s: %w", op, err)
+		}
+	}
+
+	return pl.plan, nil
+}
+
+// item is a book, chapter or page read from the directory.
+type item struct {
+	kind bookstack.ContentType
+	// path is the directory of a book or chapter, or the file of a page.
+	path  string
+	name  string
+	front FrontMatter
+	// body is the description of a book or chapter, or the content of a
+	// page.
+	body     string
+	parent   *item
+	children []*item
+	images   []*image
+	// id is set once the item is matched or created.
+	id int
+}
+
+type image struct {
+	// ref is the link to the image as written in the page.
+	ref  string
+	path string
+	// url is set once the image is found on the instance or uploaded.
+	url string
+}
+
+var imagePattern = regexp.MustCompile(`(!\[[^\]]*\]\()([^)\s]+)`)
+
+// markdown returns the body of a page with its images linked to by URL,
+// where they are known.
+func (it *item) markdown() string {
+
+	return imagePattern.ReplaceAllStringFunc(it.body, func(match string) string {
+
+		m := imagePattern.FindStringSubmatch(match)
+
+		for _, img := range it.images {
+			if img.ref == m[2] && img.url != "" {
+				return m[1] + img.url
+			}
+		}
+
+		return match
+	})
+}
+
+func (p *Publisher) abs(rel string) string {
+	return filepath.Join(p.dir, filepath.FromSlash(rel))
+}
+
+func (p *Publisher) read() ([]*item, error) {
+
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	books := []*item{}
+
+	for _, entry := range entries {
+
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		book, err := p.readDir(bookstack.ContentBook, entry.Name(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		books = append(books, book)
+	}
+
+	return books, nil
+}
+
+// readDir reads the book or chapter in the directory at rel.
+func (p *Publisher) readDir(kind bookstack.ContentType, rel string, parent *item) (*item, error) {
+
+	it := &item{kind: kind, path: rel, name: path.Base(rel), parent: parent}
+
+	entries, err := os.ReadDir(p.abs(rel))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+
+		name := entry.Name()
+
+		switch {
+		case strings.HasPrefix(name, "."):
+
+		case entry.IsDir():
+
+			if kind != bookstack.ContentBook {
+				continue
+			}
+
+			ok, err := p.hasMarkdown(path.Join(rel, name))
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				continue
+			}
+
+			chapter, err := p.readDir(bookstack.ContentChapter, path.Join(rel, name), it)
+			if err != nil {
+				return nil, err
+			}
+
+			it.children = append(it.children, chapter)
+
+		case name == IndexFile:
+
+			if err := p.readFile(it, path.Join(rel, name)); err != nil {
+				return nil, err
+			}
+
+		case strings.EqualFold(path.Ext(name), ".md"):
+
+			page := &item{kind: bookstack.ContentPage, path: path.Join(rel, name), name: strings.TrimSuffix(name, path.Ext(name)), parent: it}
+
+			if err := p.readFile(page, page.path); err != nil {
+				return nil, err
+			}
+
+			if err := p.findImages(page); err != nil {
+				return nil, err
+			}
+
+			it.children = append(it.children, page)
+		}
+	}
+
+	return it, nil
+}
+
+func (p *Publisher) hasMarkdown(rel string) (bool, error) {
+
+	entries, err := os.ReadDir(p.abs(rel))
+	if err != nil {
+		return false, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(path.Ext(entry.Name()), ".md") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// readFile reads the front matter and body of the file at rel into it.
+func (p *Publisher) readFile(it *item, rel string) error {
+
+	raw, err := os.ReadFile(p.abs(rel))
+	if err != nil {
+		return err
+	}
+
+	it.body, err = localfs.ParseFrontMatter(raw, &it.front)
+	if err != nil {
+		return fmt.Errorf("publish: %s: %w", rel, err)
+	}
+
+	if it.front.Name != "" {
+		it.name = it.front.Name
+	}
+
+	return nil
+}
+
+// findImages records the images the page links to by a relative path.
+func (p *Publisher) findImages(page *item) error {
+
+	seen := map[string]bool{}
+
+	for _, match := range imagePattern.FindAllStringSubmatch(page.body, -1) {
+
+		ref := match[2]
+
+		u, err := url.Parse(ref)
+		if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") || seen[ref] {
+			continue
+		}
+
+		seen[ref] = true
+
+		rel := path.Join(path.Dir(page.path), u.Path)
+		if rel == ".." || strings.HasPrefix(rel, "../") {
+			return fmt.Errorf("publish: %s: image %s is outside of the directory", page.path, ref)
+		}
+
+		if _, err := os.Stat(p.abs(rel)); err != nil {
+			return fmt.Errorf("publish: %s: image %s: %w", page.path, ref, err)
+		}
+
+		page.images = append(page.images, &image{ref: ref, path: rel})
+	}
+
+	return nil
+}
+
+// planner matches the items read from the directory to the instance, and
+// plans the operations between them.
+type planner struct {
+	publisher *Publisher
+	inst      *bookstack.Instance
+	plan      Plan
+	// claimed maps the items of the instance that are matched to the path
+	// they are matched to.
+	claimed map[string]string
+}
+
+func claimKey(kind bookstack.ContentType, id int) string {
+	return string(kind) + " " + strconv.Itoa(id)
+}
+
+func (pl *planner) add(op Operation, apply func(ctx context.Context) error) {
+	op.apply = apply
+	pl.plan = append(pl.plan, op)
+}
+
+// match returns the id of the item of the instance that it is published to,
+// or zero if there is none. exists reports whether an id is on the instance,
+// and slugs are the items it may match by slug.
+func (pl *planner) match(it *item, exists func(id int) bool, slugs map[string]int) (int, error) {
+
+	id := it.front.ID
+
+	if id != 0 && !exists(id) {
+		return 0, fmt.Errorf("publish: %s: %s %d: %w", it.path, it.kind, id, bookstack.ErrNotFound)
+	}
+
+	if id == 0 {
+		id = slugs[slugify(it.name)]
+	}
+
+	if id == 0 {
+		return 0, nil
+	}
+
+	key := claimKey(it.kind, id)
+
+	if other, ok := pl.claimed[key]; ok {
+		return 0, fmt.Errorf("publish: %s and %s are both %s", other, it.path, key)
+	}
+
+	pl.claimed[key] = it.path
+
+	return id, nil
+}
+
+func (pl *planner) book(ctx context.Context, it *item) error {
+
+	slugs := map[string]int{}
+	for _, book := range pl.inst.Books {
+		slugs[book.Book.Slug] = book.Book.ID
+	}
+
+	id, err := pl.match(it, func(id int) bool {
+		_, ok := pl.inst.Book(id)
+		return ok
+	}, slugs)
+	if err != nil {
+		return err
+	}
+
+	params := bookstack.BookParams{Name: it.name, Description: it.body, Tags: tagParams(it.front.Tags)}
+
+	if id == 0 {
+
+		pl.add(Operation{Action: Create, Type: bookstack.ContentBook, Name: it.name, Path: it.path}, func(ctx context.Context) error {
+
+			book, err := pl.publisher.client.CreateBook(ctx, params)
+			it.id = book.ID
+
+			return err
+		})
+
+	} else {
+
+		it.id = id
+
+		book, err := pl.publisher.client.GetBook(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if it.front.Name == "" {
+			it.name = book.Name
+			params.Name = book.Name
+		}
+
+		if it.name != book.Name || it.body != "" && it.body != strings.TrimSpace(book.Description) || !sameTags(it.front.Tags, book.Tags) {
+			pl.add(Operation{Action: Update, Type: bookstack.ContentBook, ID: id, Name: it.name, Path: it.path}, func(ctx context.Context) error {
+				_, err := pl.publisher.client.UpdateBook(ctx, id, params)
+				return err
+			})
+		}
+	}
+
+	// Chapters and pages are matched by slug within the book only.
+	remote, _ := pl.inst.Book(id)
+
+	for _, child := range it.children {
+
+		var err error
+
+		if child.kind == bookstack.ContentChapter {
+			err = pl.chapter(ctx, child, remote)
+		} else {
+			err = pl.page(ctx, child, remote)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (pl *planner) chapter(ctx context.Context, it *item, book *bookstack.InstanceBook) error {
+
+	slugs := map[string]int{}
+	if book != nil {
+		for _, chapter := range book.Chapters {
+			slugs[chapter.Chapter.Slug] = chapter.Chapter.ID
+		}
+	}
+
+	id, err := pl.match(it, func(id int) bool {
+		_, ok := pl.inst.Chapter(id)
+		return ok
+	}, slugs)
+	if err != nil {
+		return err
+	}
+
+	params := bookstack.ChapterParams{Name: it.name, Description: it.body, Tags: tagParams(it.front.Tags), Priority: it.front.Priority}
+
+	if id == 0 {
+
+		pl.add(Operation{Action: Create, Type: bookstack.ContentChapter, Name: it.name, Path: it.path}, func(ctx context.Context) error {
+
+			params.BookID = it.parent.id
+
+			chapter, err := pl.publisher.client.CreateChapter(ctx, params)
+			it.id = chapter.ID
+
+			return err
+		})
+
+	} else {
+
+		it.id = id
+
+		chapter, err := pl.publisher.client.GetChapter(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if it.front.Name == "" {
+			it.name = chapter.Name
+			params.Name = chapter.Name
+		}
+
+		// A book that is yet to be created has no id until the plan is
+		// applied, so whether the chapter moves is only known then.
+		moved := it.parent.id == 0 || it.parent.id != chapter.BookID
+
+		if moved || it.name != chapter.Name || it.body != "" && it.body != strings.TrimSpace(chapter.Description) || !sameTags(it.front.Tags, chapter.Tags) || it.front.Priority != nil && *it.front.Priority != chapter.Priority {
+			pl.add(Operation{Action: Update, Type: bookstack.ContentChapter, ID: id, Name: it.name, Path: it.path}, func(ctx context.Context) error {
+
+				if it.parent.id != chapter.BookID {
+					params.BookID = it.parent.id
+				}
+
+				_, err := pl.publisher.client.UpdateChapter(ctx, id, params)
+				return err
+			})
+		}
+	}
+
+	for _, page := range it.children {
+		if err := pl.page(ctx, page, book); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (pl *planner) page(ctx context.Context, it *item, book *bookstack.InstanceBook) error {
+
+	slugs := map[string]int{}
+	for _, page := range bookPages(book) {
+		slugs[page.Page.Slug] = page.Page.ID
+	}
+
+	id, err := pl.match(it, func(id int) bool {
+		_, ok := pl.inst.Page(id)
+		return ok
+	}, slugs)
+	if err != nil {
+		return err
+	}
+
+	if id == 0 {
+
+		pl.add(Operation{Action: Create, Type: bookstack.ContentPage, Name: it.name, Path: it.path, Images: pendingImages(it)}, func(ctx context.Context) error {
+
+			page, err := pl.publisher.client.CreatePage(ctx, pageParams(it, true))
+			if err != nil {
+				return err
+			}
+
+			it.id = page.ID
+
+			// Images belong to a page, so they can only be uploaded once it
+			// exists.
+			uploaded, err := pl.upload(ctx, it)
+			if err != nil || !uploaded {
+				return err
+			}
+
+			_, err = pl.publisher.client.UpdatePage(ctx, it.id, bookstack.PageParams{Markdown: it.markdown()})
+			return err
+		})
+
+		return nil
+	}
+
+	it.id = id
+
+	page, err := pl.publisher.client.GetPage(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if it.front.Name == "" {
+		it.name = page.Name
+	}
+
+	if len(it.images) > 0 {
+
+		images, err := pl.publisher.client.AllImages(ctx, &bookstack.QueryParams{FilterField: "uploaded_to", FilterValue: strconv.Itoa(id)})
+		if err != nil {
+			return err
+		}
+
+		for _, img := range it.images {
+			for _, existing := range images {
+				if existing.Name == path.Base(img.path) {
+					img.url = existing.URL
+					break
+				}
+			}
+		}
+	}
+
+	// A parent that is yet to be created has no id until the plan is
+	// applied, so whether the page moves is only known then.
+	chapterID, bookID := parentIDs(it)
+	pending := bookID == 0 || it.parent.kind == bookstack.ContentChapter && chapterID == 0
+	moved := pending || chapterID != page.ChapterID || bookID != page.BookID
+
+	if !moved && it.name == page.Name && it.markdown() == strings.TrimSpace(page.Markdown) && sameTags(it.front.Tags, page.Tags) && (it.front.Priority == nil || *it.front.Priority == page.Priority) {
+		return nil
+	}
+
+	pl.add(Operation{Action: Update, Type: bookstack.ContentPage, ID: id, Name: it.name, Path: it.path, Images: pendingImages(it)}, func(ctx context.Context) error {
+
+		if _, err := pl.upload(ctx, it); err != nil {
+			return err
+		}
+
+		chapterID, bookID := parentIDs(it)
+
+		_, err := pl.publisher.client.UpdatePage(ctx, id, pageParams(it, chapterID != page.ChapterID || bookID != page.BookID))
+		return err
+	})
+
+	return nil
+}
+
+// parentIDs returns the ids of the chapter and book of a page. The chapter is
+// zero for pages at the root of a book.
+func parentIDs(it *item) (int, int) {
+
+	if it.parent.kind == bookstack.ContentChapter {
+		return it.parent.id, it.parent.parent.id
+	}
+
+	return 0, it.parent.id
+}
+
+// upload uploads the images of a page that are not on the instance yet, and
+// reports whether there were any.
+func (pl *planner) upload(ctx context.Context, it *item) (bool, error) {
+
+	uploaded := false
+
+	for _, img := range it.images {
+
+		if img.url != "" {
+			continue
+		}
+
+		image, err := pl.publisher.client.CreateImage(ctx, bookstack.ImageParams{
+			Type:       bookstack.ImageGallery,
+			UploadedTo: it.id,
+			Name:       path.Base(img.path),
+			Image:      pl.publisher.abs(img.path),
+		})
+		if err != nil {
+			return uploaded, err
+		}
+
+		img.url = image.URL
+		uploaded = true
+	}
+
+	return uploaded, nil
+}
+
+// pruneBooks plans the deletes of the chapters and pages of the published
+// books that were not matched. Pages come first, so that the pages moved out
+// of a chapter are not deleted with it.
+func (pl *planner) pruneBooks(books []*item) {
+
+	for _, it := range books {
+
+		book, ok := pl.inst.Book(it.id)
+		if it.id == 0 || !ok {
+			continue
+		}
+
+		for _, page := range bookPages(book) {
+			if _, ok := pl.claimed[claimKey(bookstack.ContentPage, page.Page.ID)]; !ok {
+
+				id := page.Page.ID
+
+				pl.add(Operation{Action: Delete, Type: bookstack.ContentPage, ID: id, Name: page.Page.Name}, func(ctx context.Context) error {
+					_, err := pl.publisher.client.DeletePage(ctx, id)
+					return err
+				})
+			}
+		}
+
+		for _, chapter := range book.Chapters {
+			if _, ok := pl.claimed[claimKey(bookstack.ContentChapter, chapter.Chapter.ID)]; !ok {
+
+				id := chapter.Chapter.ID
+
+				pl.add(Operation{Action: Delete, Type: bookstack.ContentChapter, ID: id, Name: chapter.Chapter.Name}, func(ctx context.Context) error {
+					_, err := pl.publisher.client.DeleteChapter(ctx, id)
+					return err
+				})
+			}
+		}
+	}
+}
+
+// bookPages returns every page of a book, including those in chapters.
+func bookPages(book *bookstack.InstanceBook) []*bookstack.InstancePage {
+
+	if book == nil {
+		return nil
+	}
+
+	pages := append([]*bookstack.InstancePage{}, book.Pages...)
+
+	for _, chapter := range book.Chapters {
+		pages = append(pages, chapter.Pages...)
+	}
+
+	return pages
+}
+
+// pageParams returns the params a page is published with, giving its parent
+// when it is to be moved there.
+func pageParams(it *item, moved bool) bookstack.PageParams {
+
+	params := bookstack.PageParams{Name: it.name, Markdown: it.markdown(), Tags: tagParams(it.front.Tags), Priority: it.front.Priority}
+
+	if moved && it.parent.kind == bookstack.ContentChapter {
+		params.ChapterID = it.parent.id
+	} else if moved {
+		params.BookID = it.parent.id
+	}
+
+	return params
+}
+
+func pendingImages(it *item) []string {
+
+	list := []string{}
+
+	for _, img := range it.images {
+		if img.url == "" {
+			list = append(list, img.path)
+		}
+	}
+
+	return list
+}
+
+func tagParams(tags []Tag) []bookstack.TagParams {
+
+	params := []bookstack.TagParams{}
+
+	for _, tag := range tags {
+		params = append(params, bookstack.TagParams{Name: tag.Name, Value: tag.Value})
+	}
+
+	return params
+}
+
+// sameTags reports whether the tags on the instance match the ones in a file.
+// A file without tags matches any.
+func sameTags(local []Tag, remote []bookstack.Tag) bool {
+
+	if len(local) == 0 {
+		return true
+	}
+
+	if len(local) != len(remote) {
+		return false
+	}
+
+	for n := range local {
+		if local[n].Name != remote[n].Name || local[n].Value != remote[n].Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify returns the slug BookStack gives a name.
+func slugify(name string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,191 @@
+package publish_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/hcarriz/go-bookstack/publish"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+
+	p := filepath.Join(dir, filepath.FromSlash(name))
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+}
+
+func TestPublish(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	dir := t.TempDir()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Guide"})
+	check.NoError(err)
+
+	intro, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Intro", Markdown: "Old intro."})
+	check.NoError(err)
+
+	stale, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Stale", Markdown: "Gone."})
+	check.NoError(err)
+
+	writeFile(t, dir, "guide/_index.md", "---\ntags:\n  - name: team\n    value: ops\n---\nHow things work\n")
+	writeFile(t, dir, "guide/intro.md", fmt.Sprintf("---\nid: %d\nname: Introduction\n---\nNew intro.\n", intro.ID))
+	writeFile(t, dir, "guide/basics/_index.md", "---\nname: The Basics\n---\n")
+	writeFile(t, dir, "guide/basics/start.md", "---\npriority: 2\ntags:\n  - name: level\n    value: easy\n---\nStarting.\n\n![diagram](../images/diagram.png)\n")
+	writeFile(t, dir, "guide/images/diagram.png", "diagram")
+	writeFile(t, dir, "runbooks/restart.md", "Restart it.")
+
+	out := &bytes.Buffer{}
+
+	plan, err := publish.New(bk, dir, publish.DryRun(out), publish.Prune()).Run(ctx)
+	check.NoError(err)
+
+	check.Equal(strings.Join([]string{
+		fmt.Sprintf(`update book %d "Guide" from guide`, book.ID),
+		`create chapter "The Basics" from guide/basics`,
+		`create page "start" from guide/basics/start.md, uploading guide/images/diagram.png`,
+		fmt.Sprintf(`update page %d "Introduction" from guide/intro.md`, intro.ID),
+		`create book "runbooks" from runbooks`,
+		`create page "restart" from runbooks/restart.md`,
+		fmt.Sprintf(`delete page %d "Stale"`, stale.ID),
+	}, "\n")+"\n", out.String())
+	check.Len(plan, 7)
+
+	page, err := bk.GetPage(ctx, intro.ID)
+	check.NoError(err)
+	check.Equal("Old intro.", page.Markdown)
+
+	plan, err = publish.New(bk, dir, publish.Prune()).Run(ctx)
+	check.NoError(err)
+	check.Len(plan, 7)
+
+	detail, err := bk.GetBook(ctx, book.ID)
+	check.NoError(err)
+	check.Equal("Guide", detail.Name)
+	check.Equal("How things work", detail.Description)
+	check.Equal("team", detail.Tags[0].Name)
+
+	page, err = bk.GetPage(ctx, intro.ID)
+	check.NoError(err)
+	check.Equal("Introduction", page.Name)
+	check.Equal("New intro.", page.Markdown)
+
+	_, err = bk.GetPage(ctx, stale.ID)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+
+	chapters, err := bk.AllChapters(ctx, &bookstack.QueryParams{FilterField: "book_id", FilterValue: fmt.Sprint(book.ID)})
+	check.NoError(err)
+	check.Len(chapters, 1)
+	check.Equal("The Basics", chapters[0].Name)
+
+	pages, err := bk.AllPages(ctx, &bookstack.QueryParams{FilterField: "chapter_id", FilterValue: fmt.Sprint(chapters[0].ID)})
+	check.NoError(err)
+	check.Len(pages, 1)
+
+	start, err := bk.GetPage(ctx, pages[0].ID)
+	check.NoError(err)
+	check.Equal(2, start.Priority)
+	check.Equal("level", start.Tags[0].Name)
+
+	images, err := bk.AllImages(ctx, &bookstack.QueryParams{FilterField: "uploaded_to", FilterValue: fmt.Sprint(start.ID)})
+	check.NoError(err)
+	check.Len(images, 1)
+	check.Equal("diagram.png", images[0].Name)
+	check.Equal(fmt.Sprintf("Starting.\n\n![diagram](%s)", images[0].URL), start.Markdown)
+
+	plan, err = publish.New(bk, dir, publish.Prune()).Run(ctx)
+	check.NoError(err)
+	check.Empty(plan)
+
+	writeFile(t, dir, "guide/start.md", "Starting again.")
+	check.NoError(os.RemoveAll(filepath.Join(dir, "guide/basics")))
+
+	out.Reset()
+
+	_, err = publish.New(bk, dir, publish.DryRun(out), publish.Prune()).Run(ctx)
+	check.NoError(err)
+	check.Equal(strings.Join([]string{
+		fmt.Sprintf(`update page %d "start" from guide/start.md`, start.ID),
+		fmt.Sprintf(`delete chapter %d "The Basics"`, chapters[0].ID),
+	}, "\n")+"\n", out.String())
+
+	writeFile(t, dir, "guide/other.md", fmt.Sprintf("---\nid: %d\n---\nSame page.", intro.ID))
+
+	_, err = publish.New(bk, dir).Run(ctx)
+	check.ErrorContains(err, "guide/intro.md and guide/other.md are both page")
+}
+
+func TestPublishMove(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	dir := t.TempDir()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Guide"})
+	check.NoError(err)
+
+	intro, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Intro", Markdown: "Hello."})
+	check.NoError(err)
+
+	// The page moves into a chapter that does not exist yet.
+	writeFile(t, dir, "guide/newchap/intro.md", "Hello.")
+
+	out := &bytes.Buffer{}
+
+	_, err = publish.New(bk, dir, publish.DryRun(out)).Run(ctx)
+	check.NoError(err)
+	check.Equal(strings.Join([]string{
+		`create chapter "newchap" from guide/newchap`,
+		fmt.Sprintf(`update page %d "Intro" from guide/newchap/intro.md`, intro.ID),
+	}, "\n")+"\n", out.String())
+
+	_, err = publish.New(bk, dir).Run(ctx)
+	check.NoError(err)
+
+	chapters, err := bk.AllChapters(ctx, &bookstack.QueryParams{FilterField: "book_id", FilterValue: fmt.Sprint(book.ID)})
+	check.NoError(err)
+	check.Len(chapters, 1)
+
+	page, err := bk.GetPage(ctx, intro.ID)
+	check.NoError(err)
+	check.Equal(chapters[0].ID, page.ChapterID)
+
+	plan, err := publish.New(bk, dir).Run(ctx)
+	check.NoError(err)
+	check.Empty(plan)
+
+	check.NotZero(page.Priority)
+
+	writeFile(t, dir, "guide/newchap/intro.md", "---\npriority: 0\n---\nHello.")
+
+	_, err = publish.New(bk, dir).Run(ctx)
+	check.NoError(err)
+
+	page, err = bk.GetPage(ctx, intro.ID)
+	check.NoError(err)
+	check.Zero(page.Priority)
+}
@@ -0,0 +1,179 @@
+package bookstack
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a transient error are
+// retried. Throttling (429), gateway errors (502, 503, 504), and network
+// errors that are timeouts or temporary are considered transient. Errors
+// such as a rejected certificate are not.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 1 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles for every
+	// following attempt, with jitter applied.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, including delays requested
+	// by the server through Retry-After.
+	MaxDelay time.Duration
+	// RetryPOST allows POST requests to be retried. POST is not idempotent,
+	// so a retried create can produce duplicates if the first attempt
+	// reached the server.
+	RetryPOST bool
+}
+
+// DefaultRetryPolicy is used by clients created without SetRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// SetRetryPolicy sets the policy used to retry transient failures.
+func SetRetryPolicy(p RetryPolicy) Option {
+	return func(b *Bookstack) {
+		b.retry = p
+	}
+}
+
+// allows reports whether a request with method may be retried.
+func (p RetryPolicy) allows(method string) bool {
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return p.RetryPOST
+	default:
+		return false
+	}
+}
+
+// backoff returns the delay before the given retry, counting from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+
+	delay := p.BaseDelay
+
+	for i := 1; i < retry && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	// Jitter between half and the full delay, so concurrent clients spread out.
+	half := int64(delay / 2)
+
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// wait returns the delay requested by the server, if any, capped by the
+// policy.
+func (p RetryPolicy) wait(resp *http.Response, retry int) time.Duration {
+
+	delay := p.backoff(retry)
+
+	if resp != nil {
+		if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			delay = after
+		}
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return delay
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			secs = 0
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+
+		return d, true
+	}
+
+	return 0, false
+}
+
+// retryable reports whether a response status is transient.
+func retryable(status int) bool {
+
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryableErr reports whether a transport error is transient.
+func retryableErr(err error) bool {
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// Certificates are rejected the same way on every attempt.
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		invalid          x509.CertificateInvalidError
+		hostname         x509.HostnameError
+	)
+
+	if errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname) {
+		return false
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Timeout() || urlErr.Temporary()
+	}
+
+	return true
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,154 @@
+package bookstack_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/stretchr/testify/require"
+)
+
+// flaky fails the first n requests with status before answering with body.
+func flaky(n int32, status int, header http.Header, body string) (*httptest.Server, *int32) {
+
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if atomic.AddInt32(&calls, 1) <= n {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":{"code":0,"message":"try again"}}`))
+			return
+		}
+
+		w.Write([]byte(body))
+	}))
+
+	return srv, &calls
+}
+
+func TestRetry(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	policy := bookstack.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	t.Run("idempotent requests are retried", func(t *testing.T) {
+
+		srv, calls := flaky(2, http.StatusServiceUnavailable, nil, `{"data":[{"id":1,"name":"Book"}],"total":1}`)
+		defer srv.Close()
+
+		books, err := bookstack.New(bookstack.SetURL(srv.URL), bookstack.SetRetryPolicy(policy)).ListBooks(ctx, nil)
+		check.NoError(err)
+		check.Len(books, 1)
+		check.Equal(int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("attempts are limited", func(t *testing.T) {
+
+		srv, calls := flaky(5, http.StatusBadGateway, nil, `{}`)
+		defer srv.Close()
+
+		_, err := bookstack.New(bookstack.SetURL(srv.URL), bookstack.SetRetryPolicy(policy)).GetBook(ctx, 1)
+		check.Error(err)
+		check.Equal(int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("retry after is honored", func(t *testing.T) {
+
+		srv, calls := flaky(1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}}, `{"id":1}`)
+		defer srv.Close()
+
+		capped := policy
+		capped.MaxDelay = 50 * time.Millisecond
+
+		start := time.Now()
+
+		_, err := bookstack.New(bookstack.SetURL(srv.URL), bookstack.SetRetryPolicy(capped)).GetBook(ctx, 1)
+		check.NoError(err)
+		check.Equal(int32(2), atomic.LoadInt32(calls))
+		check.GreaterOrEqual(time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("post is not retried by default", func(t *testing.T) {
+
+		srv, calls := flaky(1, http.StatusServiceUnavailable, nil, `{"id":1}`)
+		defer srv.Close()
+
+		_, err := bookstack.New(bookstack.SetURL(srv.URL), bookstack.SetRetryPolicy(policy)).CreateBook(ctx, bookstack.BookParams{Name: "Book"})
+		check.Error(err)
+		check.Equal(int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("multipart post is rebuilt when retried", func(t *testing.T) {
+
+		var bodies []string
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			check.NoError(r.ParseMultipartForm(1 << 20))
+			bodies = append(bodies, r.FormValue("name"))
+
+			_, header, err := r.FormFile("image")
+			check.NoError(err)
+			check.NotZero(header.Size)
+
+			if len(bodies) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.Write([]byte(`{"id":1}`))
+		}))
+		defer srv.Close()
+
+		withPost := policy
+		withPost.RetryPOST = true
+
+		book, err := bookstack.New(bookstack.SetURL(srv.URL), bookstack.SetRetryPolicy(withPost)).CreateBook(ctx, bookstack.BookParams{
+			Name:  "Covered",
+			Image: "./test_data/upload.png",
+		})
+		check.NoError(err)
+		check.Equal(1, book.ID)
+		check.Equal("Covered,Covered", strings.Join(bodies, ","))
+	})
+
+	t.Run("rejected certificates are not retried", func(t *testing.T) {
+
+		var handshakes int32
+
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		srv.TLS = &tls.Config{GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			atomic.AddInt32(&handshakes, 1)
+			return nil, nil
+		}}
+		srv.StartTLS()
+		defer srv.Close()
+
+		_, err := bookstack.New(bookstack.SetURL(srv.URL), bookstack.SetRetryPolicy(policy)).GetBook(ctx, 1)
+		check.Error(err)
+		check.Equal(int32(1), atomic.LoadInt32(&handshakes))
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+
+		srv, calls := flaky(1, http.StatusNotFound, nil, `{"id":1}`)
+		defer srv.Close()
+
+		_, err := bookstack.New(bookstack.SetURL(srv.URL), bookstack.SetRetryPolicy(policy)).GetBook(ctx, 1)
+		check.ErrorIs(err, bookstack.ErrNotFound)
+		check.Equal(int32(1), atomic.LoadInt32(calls))
+	})
+}
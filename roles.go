@@ -0,0 +1,162 @@
+package bookstack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type Role struct {
+	ID               int       `json:"id,omitempty"`
+	DisplayName      string    `json:"display_name,omitempty"`
+	Description      string    `json:"description,omitempty"`
+	CreatedAt        time.Time `json:"created_at,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at,omitempty"`
+	SystemName       string    `json:"system_name,omitempty"`
+	ExternalAuthID   string    `json:"external_auth_id,omitempty"`
+	MFAEnforced      bool      `json:"mfa_enforced,omitempty"`
+	UsersCount       int       `json:"users_count,omitempty"`
+	PermissionsCount int       `json:"permissions_count,omitempty"`
+}
+
+type RoleDetailed struct {
+	ID             int        `json:"id,omitempty"`
+	DisplayName    string     `json:"display_name,omitempty"`
+	Description    string     `json:"description,omitempty"`
+	CreatedAt      time.Time  `json:"created_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at,omitempty"`
+	SystemName     string     `json:"system_name,omitempty"`
+	ExternalAuthID string     `json:"external_auth_id,omitempty"`
+	MFAEnforced    bool       `json:"mfa_enforced,omitempty"`
+	Permissions    []string   `json:"permissions,omitempty"`
+	Users          []RoleUser `json:"users,omitempty"`
+}
+
+type RoleUser struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug,omitempty"`
+}
+
+type RoleParams struct {
+	DisplayName    string `json:"display_name,omitempty"`
+	Description    string `json:"description,omitempty"`
+	ExternalAuthID string `json:"external_auth_id,omitempty"`
+	// MFAEnforced is left unchanged on update when nil.
+	MFAEnforced *bool `json:"mfa_enforced,omitempty"`
+	// Permissions are the system permission names granted to the role, such
+	// as "content-export" or "users-manage". They replace the existing
+	// permissions on update.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+func (rp RoleParams) Form() (string, io.Reader, error) {
+
+	r, err := json.Marshal(rp)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return appJSON, bytes.NewReader(r), nil
+}
+
+// ListRoles will return the roles that match the given params.
+func (b *Bookstack) ListRoles(ctx context.Context, params *QueryParams) ([]Role, error) {
+
+	if err := b.require(ctx, CapabilityRoles); err != nil {
+		return nil, err
+	}
+
+	resp, err := b.request(ctx, http.MethodGet, params.String("/roles"), blank{})
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseMultiple[[]Role](resp)
+}
+
+// ListRolesResult will return a page of the roles that match the given params,
+// along with the total and the pagination applied.
+func (b *Bookstack) ListRolesResult(ctx context.Context, params *QueryParams) (ListResult[Role], error) {
+
+	if err := b.require(ctx, CapabilityRoles); err != nil {
+		return ListResult[Role]{}, err
+	}
+
+	return List[Role](ctx, b, "/roles", params)
+}
+
+// AllRoles will return every role that matches the given params, following
+// pagination. The Count of params sets the page size.
+func (b *Bookstack) AllRoles(ctx context.Context, params *QueryParams) ([]Role, error) {
+
+	if err := b.require(ctx, CapabilityRoles); err != nil {
+		return nil, err
+	}
+
+	return Paginate[Role](ctx, b, "/roles", params).All()
+}
+
+// GetRole will return a single role, with its permissions and users, that
+// matches id.
+func (b *Bookstack) GetRole(ctx context.Context, id int) (RoleDetailed, error) {
+
+	if err := b.require(ctx, CapabilityRoles); err != nil {
+		return RoleDetailed{}, err
+	}
+
+	resp, err := b.request(ctx, http.MethodGet, fmt.Sprintf("/roles/%d", id), blank{})
+	if err != nil {
+		return RoleDetailed{}, err
+	}
+
+	return ParseSingle[RoleDetailed](resp)
+}
+
+// CreateRole will create a role according to the given params.
+func (b *Bookstack) CreateRole(ctx context.Context, params RoleParams) (RoleDetailed, error) {
+
+	if err := b.require(ctx, CapabilityRoles); err != nil {
+		return RoleDetailed{}, err
+	}
+
+	resp, err := b.request(ctx, http.MethodPost, "/roles", params)
+	if err != nil {
+		return RoleDetailed{}, err
+	}
+
+	return ParseSingle[RoleDetailed](resp)
+}
+
+// UpdateRole will update a role with the given params.
+func (b *Bookstack) UpdateRole(ctx context.Context, id int, params RoleParams) (RoleDetailed, error) {
+
+	if err := b.require(ctx, CapabilityRoles); err != nil {
+		return RoleDetailed{}, err
+	}
+
+	resp, err := b.request(ctx, http.MethodPut, fmt.Sprintf("/roles/%d", id), params)
+	if err != nil {
+		return RoleDetailed{}, err
+	}
+
+	return ParseSingle[RoleDetailed](resp)
+}
+
+// DeleteRole will delete a role with the given id.
+func (b *Bookstack) DeleteRole(ctx context.Context, id int) (bool, error) {
+
+	if err := b.require(ctx, CapabilityRoles); err != nil {
+		return false, err
+	}
+
+	if _, err := b.request(ctx, http.MethodDelete, fmt.Sprintf("/roles/%d", id), blank{}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,70 @@
+package bookstack_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoles(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	roles, err := bk.ListRoles(ctx, nil)
+	check.NoError(err)
+	check.Len(roles, 4)
+
+	enforced := true
+
+	created, err := bk.CreateRole(ctx, bookstack.RoleParams{
+		DisplayName:    "Auditors",
+		ExternalAuthID: "cn=auditors",
+		MFAEnforced:    &enforced,
+		Permissions:    []string{"content-export", "audit-logs-view"},
+	})
+	check.NoError(err)
+	check.True(created.MFAEnforced)
+	check.ElementsMatch([]string{"content-export", "audit-logs-view"}, created.Permissions)
+
+	user, err := bk.CreateUser(ctx, bookstack.UserParams{Name: "Ann Auditor", Email: "ann@example.com", Roles: []int{created.ID}})
+	check.NoError(err)
+	check.Equal("Auditors", user.Roles[0].DisplayName)
+
+	role, err := bk.GetRole(ctx, created.ID)
+	check.NoError(err)
+	check.Equal("cn=auditors", role.ExternalAuthID)
+	check.Equal([]bookstack.RoleUser{{ID: user.ID, Name: user.Name, Slug: user.Slug}}, role.Users)
+
+	disabled := false
+
+	updated, err := bk.UpdateRole(ctx, created.ID, bookstack.RoleParams{MFAEnforced: &disabled})
+	check.NoError(err)
+	check.False(updated.MFAEnforced)
+	check.Len(updated.Permissions, 2)
+
+	listed, err := bk.AllRoles(ctx, &bookstack.QueryParams{FilterField: "display_name", FilterValue: "Auditors"})
+	check.NoError(err)
+	check.Len(listed, 1)
+	check.Equal(1, listed[0].UsersCount)
+	check.Equal(2, listed[0].PermissionsCount)
+
+	ok, err := bk.DeleteRole(ctx, created.ID)
+	check.NoError(err)
+	check.True(ok)
+
+	_, err = bk.GetRole(ctx, created.ID)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+
+	_, err = bk.DeleteRole(ctx, 1)
+	check.ErrorIs(err, bookstack.ErrForbidden)
+}
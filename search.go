@@ -2,11 +2,15 @@ package bookstack
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/hcarriz/go-bookstack/searchquery"
 )
 
 type PreviewHTML struct {
@@ -47,6 +51,9 @@ type SearchParams struct {
 	InName *string `json:"in_name,omitempty"`
 	InBody *string `json:"in_body,omitempty"`
 
+	// Tag Filters
+	Tags []TagFilter `json:"tags,omitempty"`
+
 	// Option Filters
 	IsRestricted  bool          `json:"is_restricted,omitempty"`
 	ViewedByMe    bool          `json:"viewed_by_me,omitempty"`
@@ -59,88 +66,114 @@ type SearchParams struct {
 	Count *int
 }
 
-func (s SearchParams) String(q string) string {
-
-	l := url.Values{}
+// TagFilter matches the results with a tag that compares to Value with
+// Operator. It matches any tag called Name when Operator is empty, and tags
+// of any name when Name is empty.
+type TagFilter struct {
+	Name     string
+	Operator searchquery.Operator
+	Value    string
+}
 
-	query := []string{
-		s.Query,
-	}
+// TagExists matches results with a tag called name.
+func TagExists(name string) TagFilter {
+	return TagFilter{Name: name}
+}
 
-	if s.Page != nil {
-		l.Add("page", fmt.Sprint(s.Page))
-	}
+// TagEquals matches results with a tag called name set to value.
+func TagEquals(name, value string) TagFilter {
+	return TagFilter{Name: name, Operator: searchquery.Equals, Value: value}
+}
 
-	if s.Count != nil {
-		l.Add("count", fmt.Sprint(s.Count))
-	}
+// TagNotEquals matches results with a tag called name set to anything but
+// value.
+func TagNotEquals(name, value string) TagFilter {
+	return TagFilter{Name: name, Operator: searchquery.NotEquals, Value: value}
+}
 
-	if s.UpdatedAfter != nil {
-		query = append(query, fmt.Sprintf("{updated_after:%s}", s.UpdatedAfter.Format(SearchDateFormat)))
+// TagLike matches results with a tag called name whose value matches
+// pattern, with % as a wildcard.
+func TagLike(name, pattern string) TagFilter {
+	return TagFilter{Name: name, Operator: searchquery.Like, Value: pattern}
+}
 
-	}
+// TagNumber matches results with a tag called name whose value compares to
+// n with op, such as searchquery.Greater.
+func TagNumber(name string, op searchquery.Operator, n float64) TagFilter {
+	return TagFilter{Name: name, Operator: op, Value: strconv.FormatFloat(n, 'f', -1, 64)}
+}
 
-	if s.UpdatedBefore != nil {
-		query = append(query, fmt.Sprintf("{updated_before:%s}", s.UpdatedBefore.Format(SearchDateFormat)))
-	}
+// TagDate matches results with a tag called name whose value is a date that
+// compares to the day of t with op, such as searchquery.Less.
+func TagDate(name string, op searchquery.Operator, t time.Time) TagFilter {
+	return TagFilter{Name: name, Operator: op, Value: t.Format(SearchDateFormat)}
+}
 
-	if s.CreatedAfter != nil {
-		query = append(query, fmt.Sprintf("{updated_after:%s}", s.CreatedAfter.Format(SearchDateFormat)))
+// clauses returns the filters of the params as a query.
+func (s SearchParams) clauses() searchquery.Query {
 
-	}
+	q := searchquery.Query{}
 
-	if s.CreatedBefore != nil {
-		query = append(query, fmt.Sprintf("{updated_before:%s}", s.CreatedBefore.Format(SearchDateFormat)))
+	dates := []struct {
+		name searchquery.FilterName
+		t    *time.Time
+	}{
+		{searchquery.UpdatedAfter, s.UpdatedAfter},
+		{searchquery.UpdatedBefore, s.UpdatedBefore},
+		{searchquery.CreatedAfter, s.CreatedAfter},
+		{searchquery.CreatedBefore, s.CreatedBefore},
 	}
 
-	if s.UpdatedBy != nil {
-		by := "me"
-
-		if *s.UpdatedBy != "" {
-			by = *s.UpdatedBy
+	for _, d := range dates {
+		if d.t != nil {
+			q = append(q, searchquery.DateFilter(d.name, *d.t))
 		}
-
-		query = append(query, fmt.Sprintf("{updated_by:%s}", by))
 	}
 
-	if s.CreatedBy != nil {
-		by := "me"
+	users := []struct {
+		name searchquery.FilterName
+		by   *string
+	}{
+		{searchquery.UpdatedBy, s.UpdatedBy},
+		{searchquery.CreatedBy, s.CreatedBy},
+		{searchquery.OwnedBy, s.OwnedBy},
+	}
 
-		if *s.CreatedBy != "" {
-			by = *s.CreatedBy
-		}
+	for _, u := range users {
+		if u.by != nil {
 
-		query = append(query, fmt.Sprintf("{created_by:%s}", by))
-	}
+			by := searchquery.Me
 
-	if s.OwnedBy != nil {
-		by := "me"
+			if *u.by != "" {
+				by = *u.by
+			}
 
-		if *s.OwnedBy != "" {
-			by = *s.OwnedBy
+			q = append(q, searchquery.Filter{Name: u.name, Value: by})
 		}
-
-		query = append(query, fmt.Sprintf("{owned_by:%s}", by))
 	}
 
 	if s.InName != nil {
-		query = append(query, fmt.Sprintf("{in_name:%s}", *s.InName))
+		q = append(q, searchquery.Filter{Name: searchquery.InName, Value: *s.InName})
 	}
 
 	if s.InBody != nil {
-		query = append(query, fmt.Sprintf("{in_body:%s}", *s.InBody))
+		q = append(q, searchquery.Filter{Name: searchquery.InBody, Value: *s.InBody})
+	}
+
+	for _, t := range s.Tags {
+		q = append(q, searchquery.Tag{Name: t.Name, Operator: t.Operator, Value: t.Value})
 	}
 
 	if s.ViewedByMe {
-		query = append(query, "{viewed_by_me}")
+		q = append(q, searchquery.Filter{Name: searchquery.ViewedByMe})
 	}
 
 	if s.NotViewedByMe {
-		query = append(query, "{not_viewed_by_me}")
+		q = append(q, searchquery.Filter{Name: searchquery.NotViewedByMe})
 	}
 
 	if s.IsRestricted {
-		query = append(query, "{is_restricted}")
+		q = append(q, searchquery.Filter{Name: searchquery.IsRestricted})
 	}
 
 	if len(s.Type) > 0 {
@@ -151,21 +184,85 @@ func (s SearchParams) String(q string) string {
 			l = append(l, string(t))
 		}
 
-		query = append(query, fmt.Sprintf("{type:%s}", strings.Join(l, "|")))
+		q = append(q, searchquery.TypeFilter(l...))
+	}
+
+	return q
+}
+
+func (s SearchParams) String(q string) string {
+
+	l := url.Values{}
+
+	if s.Page != nil {
+		l.Add("page", strconv.Itoa(*s.Page))
+	}
+
+	if s.Count != nil {
+		l.Add("count", strconv.Itoa(*s.Count))
 	}
 
-	l.Add("query", strings.Join(query, " "))
+	l.Add("query", strings.TrimSpace(s.Query+" "+s.clauses().String()))
 
 	return fmt.Sprintf("%s?%s", q, l.Encode())
 }
 
 func (b *Bookstack) Search(ctx context.Context, query SearchParams) ([]Search, error) {
 
+	results, _, err := b.searchPage(ctx, query)
+
+	return results, err
+}
+
+// searchPage returns a page of results, and the total reported with it.
+func (b *Bookstack) searchPage(ctx context.Context, query SearchParams) ([]Search, int, error) {
+
+	// Values that BookStack cannot read back would otherwise silently
+	// change the search.
+	if err := query.clauses().Validate(); err != nil {
+		return nil, 0, err
+	}
+
 	raw, err := b.request(ctx, http.MethodGet, query.String("/search"), blank{})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	r := Response{}
+
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, 0, err
+	}
+
+	if r.Error() != nil {
+		return nil, 0, r.Error()
+	}
+
+	results := []Search{}
+
+	if err := json.Unmarshal(r.Data, &results); err != nil {
+		return nil, 0, err
+	}
+
+	return results, r.Total, nil
+}
+
+// SearchByTags will search for every result that matches all of the tags,
+// reading every page of results, and return them grouped by type.
+func (b *Bookstack) SearchByTags(ctx context.Context, tags ...TagFilter) (map[ContentType][]Search, error) {
+
+	grouped := map[ContentType][]Search{}
+
+	it := b.SearchAll(ctx, SearchParams{Tags: tags})
+
+	for it.Next() {
+		r := it.Result()
+		grouped[r.Type] = append(grouped[r.Type], r)
 	}
 
-	return ParseMultiple[[]Search](raw)
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
 
+	return grouped, nil
 }
@@ -0,0 +1,176 @@
+package bookstack
+
+import "context"
+
+// SearchOption configures a SearchIterator.
+type SearchOption func(*searchConfig)
+
+type searchConfig struct {
+	max int
+}
+
+// SearchMax stops the iteration after n results. Zero, the default, reads
+// every result.
+func SearchMax(n int) SearchOption {
+	return func(c *searchConfig) {
+		c.max = n
+	}
+}
+
+// maxSearchCount is the largest page size BookStack returns from a search.
+const maxSearchCount = 100
+
+// searchKey identifies a result across pages.
+type searchKey struct {
+	kind ContentType
+	id   int
+}
+
+// SearchIterator iterates over every result of a search, fetching pages
+// lazily as results are read. Content that changes during the iteration can
+// move results between pages, so results already returned are skipped. It is
+// used like bufio.Scanner:
+//
+//	it := b.SearchAll(ctx, params, SearchMax(500))
+//
+//	for it.Next() {
+//		result := it.Result()
+//	}
+//
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type SearchIterator struct {
+	ctx    context.Context
+	b      *Bookstack
+	params SearchParams
+	config searchConfig
+
+	buf    []Search
+	result Search
+	page   int
+	count  int
+	total  int
+	read   int
+	seen   map[searchKey]bool
+	done   bool
+	err    error
+}
+
+// SearchAll returns an iterator over every result of the search. The Count
+// of params sets the page size, up to 100, and Page the first page to read.
+func (b *Bookstack) SearchAll(ctx context.Context, params SearchParams, opts ...SearchOption) *SearchIterator {
+
+	it := &SearchIterator{
+		ctx:    ctx,
+		b:      b,
+		params: params,
+		page:   1,
+		count:  defaultCount,
+		seen:   map[searchKey]bool{},
+	}
+
+	if params.Page != nil && *params.Page > 1 {
+		it.page = *params.Page
+	}
+
+	if params.Count != nil && *params.Count > 0 {
+		it.count = *params.Count
+	}
+
+	if it.count > maxSearchCount {
+		it.count = maxSearchCount
+	}
+
+	for _, opt := range opts {
+		opt(&it.config)
+	}
+
+	return it
+}
+
+// Next advances to the next result, fetching a page if needed. It returns
+// false when there are no more results, the maximum is reached, or an error
+// occurred.
+func (it *SearchIterator) Next() bool {
+
+	if it.config.max > 0 && it.read >= it.config.max {
+		return false
+	}
+
+	for {
+
+		for len(it.buf) > 0 {
+
+			r := it.buf[0]
+			it.buf = it.buf[1:]
+
+			key := searchKey{r.Type, r.ID}
+
+			if it.seen[key] {
+				continue
+			}
+
+			it.seen[key] = true
+			it.result = r
+			it.read++
+
+			return true
+		}
+
+		if it.done || it.err != nil {
+			return false
+		}
+
+		page, count := it.page, it.count
+
+		params := it.params
+		params.Page, params.Count = &page, &count
+
+		results, total, err := it.b.searchPage(it.ctx, params)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.total = total
+		it.buf = results
+
+		// BookStack can return short pages, as results the user cannot see
+		// are removed after paging, so only an empty page or the total ends
+		// the results.
+		if len(results) == 0 || it.page*it.count >= total {
+			it.done = true
+		}
+
+		it.page++
+	}
+}
+
+// Result returns the current result.
+func (it *SearchIterator) Result() Search {
+	return it.result
+}
+
+// Err returns the first error that stopped the iteration.
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// Total returns the number of results reported by the server with the last
+// page read. It is zero until the first call to Next.
+func (it *SearchIterator) Total() int {
+	return it.total
+}
+
+// All reads every remaining result.
+func (it *SearchIterator) All() ([]Search, error) {
+
+	results := []Search{}
+
+	for it.Next() {
+		results = append(results, it.Result())
+	}
+
+	return results, it.Err()
+}
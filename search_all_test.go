@@ -0,0 +1,91 @@
+package bookstack_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchAll(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Guide"})
+	check.NoError(err)
+
+	pages := []int{}
+
+	for i := 0; i < 5; i++ {
+
+		page, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: fmt.Sprintf("Shift %d", i), HTML: "<p>Page</p>"})
+		check.NoError(err)
+
+		pages = append(pages, page.ID)
+	}
+
+	count := 2
+
+	it := bk.SearchAll(ctx, bookstack.SearchParams{Query: "shift", Count: &count})
+
+	ids := []int{}
+
+	for n := 0; n < 2; n++ {
+		check.True(it.Next())
+		ids = append(ids, it.Result().ID)
+	}
+
+	check.Equal(5, it.Total())
+
+	// Books come first in the results, so a new book moves every page
+	// down, and the second page starts with a page that was already read.
+	_, err = bk.CreateBook(ctx, bookstack.BookParams{Name: "Shift book"})
+	check.NoError(err)
+
+	rest, err := it.All()
+	check.NoError(err)
+
+	for _, r := range rest {
+		ids = append(ids, r.ID)
+	}
+
+	check.Equal(pages, ids)
+	check.Equal(6, it.Total())
+
+	results, err := bk.SearchAll(ctx, bookstack.SearchParams{Query: "shift", Count: &count}, bookstack.SearchMax(3)).All()
+	check.NoError(err)
+	check.Len(results, 3)
+
+	start := 3
+
+	results, err = bk.SearchAll(ctx, bookstack.SearchParams{Query: "shift", Count: &count, Page: &start}).All()
+	check.NoError(err)
+	check.Len(results, 2)
+	check.Equal(pages[3:], []int{results[0].ID, results[1].ID})
+
+	for i := 0; i < 150; i++ {
+		_, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: fmt.Sprintf("Bulk %d", i), HTML: "<p>Page</p>"})
+		check.NoError(err)
+	}
+
+	// Counts above what the server returns are lowered to it.
+	large := 250
+
+	results, err = bk.SearchAll(ctx, bookstack.SearchParams{Query: "bulk", Count: &large}).All()
+	check.NoError(err)
+	check.Len(results, 150)
+
+	it = bk.SearchAll(ctx, bookstack.SearchParams{Query: `"open`})
+	check.False(it.Next())
+	check.ErrorIs(it.Err(), bookstack.ErrValidation)
+}
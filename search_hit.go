@@ -0,0 +1,209 @@
+package bookstack
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// SearchHit is a search result of a known type. It is one of BookHit,
+// ChapterHit, PageHit or ShelfHit.
+type SearchHit interface {
+	// Result returns the search result of the hit.
+	Result() Search
+	searchHit()
+}
+
+// BookHit is a book found by a search.
+type BookHit struct {
+	Search
+	// Book is set by Resolve.
+	Book *BookDetailed
+}
+
+// ChapterHit is a chapter found by a search.
+type ChapterHit struct {
+	Search
+	// Chapter is set by Resolve.
+	Chapter *ChapterDetailed
+}
+
+// PageHit is a page found by a search.
+type PageHit struct {
+	Search
+	// Page is set by Resolve.
+	Page *PageDetailed
+}
+
+// ShelfHit is a shelf found by a search.
+type ShelfHit struct {
+	Search
+	// Shelf is set by Resolve.
+	Shelf *ShelfDetailed
+}
+
+func (h BookHit) Result() Search    { return h.Search }
+func (h ChapterHit) Result() Search { return h.Search }
+func (h PageHit) Result() Search    { return h.Search }
+func (h ShelfHit) Result() Search   { return h.Search }
+
+func (BookHit) searchHit()    {}
+func (ChapterHit) searchHit() {}
+func (PageHit) searchHit()    {}
+func (ShelfHit) searchHit()   {}
+
+// Hit returns the result as a SearchHit, or false if its type is unknown.
+func (s Search) Hit() (SearchHit, bool) {
+
+	switch s.Type {
+	case ContentBook:
+		return BookHit{Search: s}, true
+	case ContentChapter:
+		return ChapterHit{Search: s}, true
+	case ContentPage:
+		return PageHit{Search: s}, true
+	case ContentShelf:
+		return ShelfHit{Search: s}, true
+	}
+
+	return nil, false
+}
+
+// resolveWorkers is the number of requests Resolve makes at once.
+const resolveWorkers = 4
+
+// Resolve will read every result in full, returning them as hits in the same
+// order with their Book, Chapter, Page or Shelf set. Results of unknown types
+// are left out. The requests are made a few at a time, and the first that
+// fails cancels the rest.
+func (b *Bookstack) Resolve(ctx context.Context, results []Search) ([]SearchHit, error) {
+
+	hits := make([]SearchHit, 0, len(results))
+
+	for _, r := range results {
+		if hit, ok := r.Hit(); ok {
+			hits = append(hits, hit)
+		}
+	}
+
+	if err := runPool(ctx, resolveWorkers, len(hits), func(ctx context.Context, n int) error {
+
+		var err error
+
+		switch hit := hits[n].(type) {
+		case BookHit:
+			var book BookDetailed
+			book, err = b.GetBook(ctx, hit.ID)
+			hit.Book = &book
+			hits[n] = hit
+
+		case ChapterHit:
+			var chapter ChapterDetailed
+			chapter, err = b.GetChapter(ctx, hit.ID)
+			hit.Chapter = &chapter
+			hits[n] = hit
+
+		case PageHit:
+			var page PageDetailed
+			page, err = b.GetPage(ctx, hit.ID)
+			hit.Page = &page
+			hits[n] = hit
+
+		case ShelfHit:
+			var shelf ShelfDetailed
+			shelf, err = b.GetShelf(ctx, hit.ID)
+			hit.Shelf = &shelf
+			hits[n] = hit
+		}
+
+		if err != nil {
+			return fmt.Errorf("bookstack: resolving %s %d: %w", hits[n].Result().Type, hits[n].Result().ID, err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
+
+// Snippet is the plain text of a search preview, with the parts BookStack
+// highlighted as matching the search.
+type Snippet struct {
+	Text    string
+	Matches []SnippetMatch
+}
+
+// SnippetMatch is the byte offsets of a match in Snippet.Text.
+type SnippetMatch struct {
+	Start int
+	End   int
+}
+
+// NameSnippet returns the name of the preview as a Snippet.
+func (p PreviewHTML) NameSnippet() Snippet {
+	return ParseSnippet(p.Name)
+}
+
+// ContentSnippet returns the content of the preview as a Snippet.
+func (p PreviewHTML) ContentSnippet() Snippet {
+	return ParseSnippet(p.Content)
+}
+
+// ParseSnippet strips the markup from the HTML of a search preview, noting
+// where the text in <strong> or <mark> elements ends up.
+func ParseSnippet(s string) Snippet {
+
+	var text strings.Builder
+
+	snippet := Snippet{Matches: []SnippetMatch{}}
+	start := -1
+
+	for len(s) > 0 {
+
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			text.WriteString(html.UnescapeString(s))
+			break
+		}
+
+		text.WriteString(html.UnescapeString(s[:lt]))
+
+		gt := strings.IndexByte(s[lt:], '>')
+		if gt < 0 {
+			text.WriteString(html.UnescapeString(s[lt:]))
+			break
+		}
+
+		tag := strings.ToLower(strings.TrimSpace(s[lt+1 : lt+gt]))
+		s = s[lt+gt+1:]
+
+		closing := strings.HasPrefix(tag, "/")
+		name := strings.TrimPrefix(tag, "/")
+
+		if i := strings.IndexAny(name, " \t\n/"); i >= 0 {
+			name = name[:i]
+		}
+
+		if name != "strong" && name != "mark" {
+			continue
+		}
+
+		switch {
+		case !closing && start < 0:
+			start = text.Len()
+
+		case closing && start >= 0:
+			if text.Len() > start {
+				snippet.Matches = append(snippet.Matches, SnippetMatch{Start: start, End: text.Len()})
+			}
+			start = -1
+		}
+	}
+
+	snippet.Text = text.String()
+
+	return snippet
+}
@@ -0,0 +1,88 @@
+package bookstack_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Alpha book"})
+	check.NoError(err)
+
+	chapter, err := bk.CreateChapter(ctx, bookstack.ChapterParams{BookID: book.ID, Name: "Alpha chapter"})
+	check.NoError(err)
+
+	page, err := bk.CreatePage(ctx, bookstack.PageParams{ChapterID: chapter.ID, Name: "Page", HTML: "<p>Tom &amp; Jerry meet alpha and ALPHA.</p>"})
+	check.NoError(err)
+
+	shelf, err := bk.CreateShelf(ctx, bookstack.ShelfParams{Name: "Alpha shelf", Books: []int{book.ID}})
+	check.NoError(err)
+
+	results, err := bk.Search(ctx, bookstack.SearchParams{Query: "alpha"})
+	check.NoError(err)
+	check.Len(results, 4)
+
+	hits, err := bk.Resolve(ctx, results)
+	check.NoError(err)
+	check.Len(hits, 4)
+
+	for n, hit := range hits {
+
+		check.Equal(results[n], hit.Result())
+
+		switch hit := hit.(type) {
+		case bookstack.BookHit:
+			check.Equal(book.ID, hit.Book.ID)
+		case bookstack.ChapterHit:
+			check.Equal(chapter.Name, hit.Chapter.Name)
+		case bookstack.PageHit:
+			check.Equal(page.ID, hit.Page.ID)
+			check.Contains(hit.Page.HTML, "Jerry")
+
+			snippet := hit.PreviewHTML.ContentSnippet()
+			check.Equal("Tom & Jerry meet alpha and ALPHA.", snippet.Text)
+			check.Equal([]bookstack.SnippetMatch{{Start: 17, End: 22}, {Start: 27, End: 32}}, snippet.Matches)
+		case bookstack.ShelfHit:
+			check.Equal(shelf.ID, hit.Shelf.ID)
+			check.Len(hit.Shelf.Books, 1)
+		default:
+			t.Fatalf("unexpected hit %T", hit)
+		}
+	}
+
+	_, ok := bookstack.Search{Type: bookstack.ContentAttachment}.Hit()
+	check.False(ok)
+
+	failing := srv.Client(bookstack.SetTransport(failingTransport{path: fmt.Sprintf("/api/chapters/%d", chapter.ID)}))
+
+	_, err = failing.Resolve(ctx, results)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+}
+
+func TestParseSnippet(t *testing.T) {
+
+	check := require.New(t)
+
+	snippet := bookstack.ParseSnippet(`<p>Use <strong>go</strong> &lt;1.18&gt;, not <b>rust</b> or <mark class="hl">Zig</mark><strong></strong></p>`)
+	check.Equal("Use go <1.18>, not rust or Zig", snippet.Text)
+	check.Equal([]bookstack.SnippetMatch{{Start: 4, End: 6}, {Start: 27, End: 30}}, snippet.Matches)
+
+	snippet = bookstack.ParseSnippet("no markup & <unclosed")
+	check.Equal("no markup & <unclosed", snippet.Text)
+	check.Empty(snippet.Matches)
+}
@@ -0,0 +1,133 @@
+package bookstack_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/hcarriz/go-bookstack/searchquery"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchParams(t *testing.T) {
+
+	check := require.New(t)
+
+	after := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)
+	me, name := "", "on call"
+
+	raw := bookstack.SearchParams{
+		Query:         "deploy",
+		CreatedAfter:  &after,
+		CreatedBefore: &before,
+		OwnedBy:       &me,
+		InName:        &name,
+		Type:          []bookstack.ContentType{bookstack.ContentPage, bookstack.ContentChapter},
+	}.String("/search")
+
+	u, err := url.Parse(raw)
+	check.NoError(err)
+	check.Equal("deploy {created_after:2024-01-02} {created_before:2024-02-03} {owned_by:me} {in_name:on call} {type:page|chapter}", u.Query().Get("query"))
+
+	// Without a query there is no leading space.
+	check.Equal("?query=%7Bviewed_by_me%7D", bookstack.SearchParams{ViewedByMe: true}.String(""))
+}
+
+func TestSearch(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Guide"})
+	check.NoError(err)
+
+	_, err = bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Deploy notes", HTML: "<p>Rolling deploy</p>", Tags: []bookstack.TagParams{{Name: "priority", Value: "5"}}})
+	check.NoError(err)
+
+	_, err = bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Deploy legacy", HTML: "<p>Old deploy</p>", Tags: []bookstack.TagParams{{Name: "priority", Value: "10"}}})
+	check.NoError(err)
+
+	results, err := bk.Search(ctx, bookstack.SearchParams{Query: `deploy -legacy`})
+	check.NoError(err)
+	check.Len(results, 1)
+	check.Equal("Deploy notes", results[0].Name)
+
+	results, err = bk.Search(ctx, bookstack.SearchParams{Query: `[priority>6]`})
+	check.NoError(err)
+	check.Len(results, 1)
+	check.Equal("Deploy legacy", results[0].Name)
+
+	results, err = bk.Search(ctx, bookstack.SearchParams{Query: `"rolling deploy"`})
+	check.NoError(err)
+	check.Len(results, 1)
+
+	name := "a}b"
+
+	_, err = bk.Search(ctx, bookstack.SearchParams{Query: "deploy", InName: &name})
+	check.Error(err)
+
+	_, err = bk.Search(ctx, bookstack.SearchParams{Query: `"open`})
+	check.ErrorIs(err, bookstack.ErrValidation)
+	check.ErrorContains(err, "phrase is not closed")
+}
+
+func TestSearchByTags(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Guide", Tags: []bookstack.TagParams{{Name: "status", Value: "draft"}}})
+	check.NoError(err)
+
+	for i := 0; i < 105; i++ {
+
+		tags := []bookstack.TagParams{{Name: "status", Value: "draft"}, {Name: "priority", Value: fmt.Sprint(i)}}
+
+		if i%2 == 0 {
+			tags = append(tags, bookstack.TagParams{Name: "review", Value: "2024-0" + fmt.Sprint(1+i%9) + "-15"})
+		}
+
+		_, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: fmt.Sprintf("Page %d", i), HTML: "<p>Page</p>", Tags: tags})
+		check.NoError(err)
+	}
+
+	grouped, err := bk.SearchByTags(ctx, bookstack.TagEquals("status", "draft"))
+	check.NoError(err)
+	check.Len(grouped[bookstack.ContentPage], 105)
+	check.Len(grouped[bookstack.ContentBook], 1)
+
+	grouped, err = bk.SearchByTags(ctx, bookstack.TagEquals("status", "draft"), bookstack.TagNumber("priority", searchquery.GreaterOrEqual, 100))
+	check.NoError(err)
+	check.Len(grouped[bookstack.ContentPage], 5)
+	check.Empty(grouped[bookstack.ContentBook])
+
+	grouped, err = bk.SearchByTags(ctx, bookstack.TagExists("review"), bookstack.TagDate("review", searchquery.Less, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)))
+	check.NoError(err)
+	check.Len(grouped[bookstack.ContentPage], 6)
+
+	grouped, err = bk.SearchByTags(ctx, bookstack.TagLike("priority", "10%"), bookstack.TagNotEquals("priority", "10"))
+	check.NoError(err)
+	check.Len(grouped[bookstack.ContentPage], 5)
+
+	results, err := bk.Search(ctx, bookstack.SearchParams{Query: "page", Tags: []bookstack.TagFilter{bookstack.TagEquals("priority", "7")}, Type: []bookstack.ContentType{bookstack.ContentPage}})
+	check.NoError(err)
+	check.Len(results, 1)
+	check.Equal("Page 7", results[0].Name)
+}
@@ -0,0 +1,447 @@
+// Package searchquery builds and parses BookStack search queries. A query is
+// a list of clauses, all of which a result must match:
+//
+//	deploy "release notes" [status=draft] {type:page} -{is_template}
+//
+// Query.String writes a query the way BookStack reads it, and Parse reads
+// one back, so that Parse(q.String()) returns q for every query that passes
+// Validate.
+package searchquery
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DateFormat is the format of the values of the date filters.
+const DateFormat = "2006-01-02"
+
+// Query is a search, made of clauses that must all match.
+type Query []Clause
+
+// String returns the query as BookStack reads it.
+func (q Query) String() string {
+
+	parts := make([]string, 0, len(q))
+
+	for _, c := range q {
+		parts = append(parts, c.String())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Validate returns an error for the first clause that cannot be written in a
+// way BookStack reads back as the same clause.
+func (q Query) Validate() error {
+
+	for _, c := range q {
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("searchquery: %s: %w", c, err)
+		}
+	}
+
+	return nil
+}
+
+// Filters returns the filters of the query with the given name.
+func (q Query) Filters(name FilterName) []Filter {
+
+	list := []Filter{}
+
+	for _, c := range q {
+		if f, ok := c.(Filter); ok && f.Name == name {
+			list = append(list, f)
+		}
+	}
+
+	return list
+}
+
+// Clause is a Term, Exact, Tag or Filter.
+type Clause interface {
+	String() string
+	validate() error
+}
+
+// negate returns the prefix of a negated clause.
+func negate(negated bool) string {
+
+	if negated {
+		return "-"
+	}
+
+	return ""
+}
+
+// Term is a single word to search for.
+type Term struct {
+	Value   string
+	Negated bool
+}
+
+func (t Term) String() string {
+	return negate(t.Negated) + t.Value
+}
+
+func (t Term) validate() error {
+
+	switch {
+	case t.Value == "":
+		return errors.New("empty term")
+	case strings.IndexFunc(t.Value, unicode.IsSpace) >= 0:
+		return errors.New("terms are single words, use Exact for phrases")
+	case strings.ContainsAny(t.Value[:1], `"[{-`):
+		return fmt.Errorf("terms cannot start with %q", t.Value[:1])
+	}
+
+	return nil
+}
+
+// Exact is a phrase that must be found as it is.
+type Exact struct {
+	Value   string
+	Negated bool
+}
+
+var exactEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func (e Exact) String() string {
+	return negate(e.Negated) + `"` + exactEscaper.Replace(e.Value) + `"`
+}
+
+func (e Exact) validate() error {
+
+	if e.Value == "" {
+		return errors.New("empty phrase")
+	}
+
+	return nil
+}
+
+// Operator compares the value of a tag.
+type Operator string
+
+const (
+	Equals         Operator = "="
+	NotEquals      Operator = "!="
+	Less           Operator = "<"
+	Greater        Operator = ">"
+	LessOrEqual    Operator = "<="
+	GreaterOrEqual Operator = ">="
+	// Like matches values with % as a wildcard.
+	Like Operator = "like"
+)
+
+// operators are in the order they are looked for, so that <= is not read as
+// <.
+var operators = []Operator{LessOrEqual, GreaterOrEqual, NotEquals, Equals, Less, Greater}
+
+// Tag matches items by their tags. A Tag without an Operator matches items
+// that have a tag with Name, whatever its value. A Tag without a Name
+// matches items with a tag of any name.
+type Tag struct {
+	Name     string
+	Operator Operator
+	Value    string
+	Negated  bool
+}
+
+func (t Tag) String() string {
+
+	if t.Operator == "" {
+		return negate(t.Negated) + "[" + t.Name + "]"
+	}
+
+	if t.Operator == Like {
+		return negate(t.Negated) + "[" + t.Name + " like " + t.Value + "]"
+	}
+
+	return negate(t.Negated) + "[" + t.Name + string(t.Operator) + t.Value + "]"
+}
+
+func (t Tag) validate() error {
+
+	if t.Operator == "" && t.Value != "" {
+		return errors.New("tag value without an operator")
+	}
+
+	if t.Operator == "" && t.Name == "" {
+		return errors.New("empty tag")
+	}
+
+	if t.Operator != "" && t.Operator != Like && operatorIndex(t.Operator) < 0 {
+		return fmt.Errorf("unknown operator %q", t.Operator)
+	}
+
+	for _, s := range []string{t.Name, t.Value} {
+
+		if strings.ContainsAny(s, `]"`) {
+			return errors.New(`tags cannot hold "]" or '"'`)
+		}
+
+		if s != strings.TrimSpace(s) {
+			return errors.New("tags cannot start or end with spaces")
+		}
+	}
+
+	if strings.ContainsAny(t.Name, "=<>!") || strings.Contains(t.Name, " like ") {
+		return errors.New("tag names cannot hold operators")
+	}
+
+	// [n<=5] is read as <= rather than < with a value of =5.
+	if (t.Operator == Less || t.Operator == Greater) && strings.HasPrefix(t.Value, "=") {
+		return fmt.Errorf("values compared with %s cannot start with =", t.Operator)
+	}
+
+	return nil
+}
+
+func operatorIndex(op Operator) int {
+
+	for n, o := range operators {
+		if o == op {
+			return n
+		}
+	}
+
+	return -1
+}
+
+// FilterName is the name of a filter BookStack supports.
+type FilterName string
+
+const (
+	// Date filters take a date in DateFormat.
+	UpdatedAfter  FilterName = "updated_after"
+	UpdatedBefore FilterName = "updated_before"
+	CreatedAfter  FilterName = "created_after"
+	CreatedBefore FilterName = "created_before"
+
+	// User filters take the slug of a user, or "me".
+	UpdatedBy FilterName = "updated_by"
+	CreatedBy FilterName = "created_by"
+	OwnedBy   FilterName = "owned_by"
+
+	// Content filters take the text to look for.
+	InName FilterName = "in_name"
+	InBody FilterName = "in_body"
+
+	// Location filters take the id of a book or chapter.
+	InBook    FilterName = "in_book"
+	InChapter FilterName = "in_chapter"
+
+	// Option filters take no value.
+	IsRestricted  FilterName = "is_restricted"
+	IsTemplate    FilterName = "is_template"
+	ViewedByMe    FilterName = "viewed_by_me"
+	NotViewedByMe FilterName = "not_viewed_by_me"
+
+	// Type takes the types of item to return, separated by |.
+	Type FilterName = "type"
+	// SortBy takes the order of the results, such as SortLastCommented.
+	SortBy FilterName = "sort_by"
+)
+
+// SortLastCommented is the value of SortBy that orders results by their
+// latest comment.
+const SortLastCommented = "last_commented"
+
+// Me is the value of the user filters that matches the current user.
+const Me = "me"
+
+// Filter narrows the results by one of their properties.
+type Filter struct {
+	Name    FilterName
+	Value   string
+	Negated bool
+}
+
+// DateFilter returns a date filter, such as UpdatedAfter, for t.
+func DateFilter(name FilterName, t time.Time) Filter {
+	return Filter{Name: name, Value: t.Format(DateFormat)}
+}
+
+// IDFilter returns a location filter, such as InBook, for id.
+func IDFilter(name FilterName, id int) Filter {
+	return Filter{Name: name, Value: strconv.Itoa(id)}
+}
+
+// TypeFilter returns a Type filter for the given types.
+func TypeFilter(types ...string) Filter {
+	return Filter{Name: Type, Value: strings.Join(types, "|")}
+}
+
+// Date returns the value of a date filter.
+func (f Filter) Date() (time.Time, error) {
+	return time.Parse(DateFormat, f.Value)
+}
+
+// ID returns the value of a location filter.
+func (f Filter) ID() (int, error) {
+	return strconv.Atoi(f.Value)
+}
+
+// Types returns the value of a Type filter.
+func (f Filter) Types() []string {
+
+	if f.Value == "" {
+		return nil
+	}
+
+	return strings.Split(f.Value, "|")
+}
+
+func (f Filter) String() string {
+
+	if f.Value == "" {
+		return negate(f.Negated) + "{" + string(f.Name) + "}"
+	}
+
+	return negate(f.Negated) + "{" + string(f.Name) + ":" + f.Value + "}"
+}
+
+func (f Filter) validate() error {
+
+	if f.Name == "" {
+		return errors.New("empty filter")
+	}
+
+	if strings.ContainsAny(string(f.Name), `:}"`) || strings.ContainsAny(f.Value, `}"`) {
+		return errors.New(`filters cannot hold "}" or '"'`)
+	}
+
+	if f.Value != strings.TrimSpace(f.Value) || string(f.Name) != strings.TrimSpace(string(f.Name)) {
+		return errors.New("filters cannot start or end with spaces")
+	}
+
+	return nil
+}
+
+// SyntaxError is returned by Parse for a query that is not closed.
+type SyntaxError struct {
+	// Offset is the byte offset in the query of the clause that is not
+	// closed.
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("searchquery: %s at offset %d", e.Msg, e.Offset)
+}
+
+// Parse reads a query. Phrases, tags and filters that are not closed are a
+// *SyntaxError.
+func Parse(s string) (Query, error) {
+
+	q := Query{}
+
+	for i := 0; i < len(s); {
+
+		if isSpace(s[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		negated := false
+
+		if s[i] == '-' && i+1 < len(s) && !isSpace(s[i+1]) {
+			negated = true
+			i++
+		}
+
+		switch s[i] {
+		case '"':
+
+			value, end, ok := readExact(s, i+1)
+			if !ok {
+				return nil, &SyntaxError{Offset: start, Msg: "phrase is not closed"}
+			}
+
+			q = append(q, Exact{Value: value, Negated: negated})
+			i = end
+
+		case '[':
+
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, &SyntaxError{Offset: start, Msg: "tag is not closed"}
+			}
+
+			q = append(q, parseTag(s[i+1:i+end], negated))
+			i += end + 1
+
+		case '{':
+
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, &SyntaxError{Offset: start, Msg: "filter is not closed"}
+			}
+
+			name, value, _ := strings.Cut(s[i+1:i+end], ":")
+
+			q = append(q, Filter{Name: FilterName(strings.TrimSpace(name)), Value: strings.TrimSpace(value), Negated: negated})
+			i += end + 1
+
+		default:
+
+			end := i
+			for end < len(s) && !isSpace(s[end]) {
+				end++
+			}
+
+			q = append(q, Term{Value: s[i:end], Negated: negated})
+			i = end
+		}
+	}
+
+	return q, nil
+}
+
+// readExact reads a phrase from just after its opening quote, and returns it
+// with the offset just after its closing quote.
+func readExact(s string, i int) (string, int, bool) {
+
+	var b strings.Builder
+
+	for ; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\'):
+			b.WriteByte(s[i+1])
+			i++
+		case s[i] == '"':
+			return b.String(), i + 1, true
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return "", i, false
+}
+
+func parseTag(body string, negated bool) Tag {
+
+	first, found := strings.Index(body, " like "), Like
+	width := len(" like ")
+
+	for _, op := range operators {
+		if n := strings.Index(body, string(op)); n >= 0 && (first < 0 || n < first) {
+			first, found, width = n, op, len(op)
+		}
+	}
+
+	if first < 0 {
+		return Tag{Name: strings.TrimSpace(body), Negated: negated}
+	}
+
+	return Tag{Name: strings.TrimSpace(body[:first]), Operator: found, Value: strings.TrimSpace(body[first+width:]), Negated: negated}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,103 @@
+package searchquery_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hcarriz/go-bookstack/searchquery"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+
+	check := require.New(t)
+
+	q, err := searchquery.Parse(`deploy  -legacy "release \"notes\"" -"old" [status=draft] [priority>=3] [owner] [=urgent] -[stage like dev%] {type:page|chapter} -{is_template} {in_name: on call }`)
+	check.NoError(err)
+
+	check.Equal(searchquery.Query{
+		searchquery.Term{Value: "deploy"},
+		searchquery.Term{Value: "legacy", Negated: true},
+		searchquery.Exact{Value: `release "notes"`},
+		searchquery.Exact{Value: "old", Negated: true},
+		searchquery.Tag{Name: "status", Operator: searchquery.Equals, Value: "draft"},
+		searchquery.Tag{Name: "priority", Operator: searchquery.GreaterOrEqual, Value: "3"},
+		searchquery.Tag{Name: "owner"},
+		searchquery.Tag{Operator: searchquery.Equals, Value: "urgent"},
+		searchquery.Tag{Name: "stage", Operator: searchquery.Like, Value: "dev%", Negated: true},
+		searchquery.TypeFilter("page", "chapter"),
+		searchquery.Filter{Name: searchquery.IsTemplate, Negated: true},
+		searchquery.Filter{Name: searchquery.InName, Value: "on call"},
+	}, q)
+
+	check.NoError(q.Validate())
+	check.Equal(`deploy -legacy "release \"notes\"" -"old" [status=draft] [priority>=3] [owner] [=urgent] -[stage like dev%] {type:page|chapter} -{is_template} {in_name:on call}`, q.String())
+
+	check.Equal([]string{"page", "chapter"}, q.Filters(searchquery.Type)[0].Types())
+
+	for _, s := range []string{`"open`, `[status=draft`, `{type:page`} {
+
+		_, err := searchquery.Parse(s)
+
+		var syntax *searchquery.SyntaxError
+		check.ErrorAs(err, &syntax)
+		check.Equal(0, syntax.Offset)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	check := require.New(t)
+
+	day := time.Date(2024, 3, 9, 15, 4, 5, 0, time.UTC)
+
+	q := searchquery.Query{
+		searchquery.Term{Value: "a-b"},
+		searchquery.Exact{Value: `back\slash "quoted" {braces} [brackets]`},
+		searchquery.Tag{Name: "cost", Operator: searchquery.LessOrEqual, Value: "30"},
+		searchquery.Tag{Name: "stage", Operator: searchquery.NotEquals, Value: "a=b"},
+		searchquery.Tag{Name: "team", Operator: searchquery.Like, Value: "ops=%"},
+		searchquery.Tag{Name: "archived", Negated: true},
+		searchquery.Tag{Name: "n", Operator: searchquery.Equals, Value: "=5"},
+		searchquery.Tag{Name: "n", Operator: searchquery.LessOrEqual, Value: "=5"},
+		searchquery.DateFilter(searchquery.UpdatedAfter, day),
+		searchquery.IDFilter(searchquery.InBook, 12),
+		searchquery.IDFilter(searchquery.InChapter, 4),
+		searchquery.Filter{Name: searchquery.SortBy, Value: searchquery.SortLastCommented},
+		searchquery.Filter{Name: searchquery.OwnedBy, Value: searchquery.Me},
+		searchquery.Filter{Name: searchquery.InBody, Value: "two words: [x]"},
+		searchquery.Filter{Name: searchquery.ViewedByMe, Negated: true},
+	}
+
+	check.NoError(q.Validate())
+
+	parsed, err := searchquery.Parse(q.String())
+	check.NoError(err)
+	check.Equal(q, parsed)
+
+	date, err := parsed.Filters(searchquery.UpdatedAfter)[0].Date()
+	check.NoError(err)
+	check.Equal(time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), date)
+
+	id, err := parsed.Filters(searchquery.InBook)[0].ID()
+	check.NoError(err)
+	check.Equal(12, id)
+
+	for _, c := range []searchquery.Clause{
+		searchquery.Term{Value: "two words"},
+		searchquery.Term{Value: "-dash"},
+		searchquery.Exact{},
+		searchquery.Tag{Name: "a]b"},
+		searchquery.Tag{Name: "a<b", Operator: searchquery.Equals, Value: "c"},
+		searchquery.Tag{Name: "a", Value: "b"},
+		searchquery.Tag{Name: "a", Operator: "~", Value: "b"},
+		searchquery.Tag{Name: "n", Operator: searchquery.Less, Value: "=5"},
+		searchquery.Tag{Name: "n", Operator: searchquery.Greater, Value: "=5"},
+		searchquery.Filter{Name: " x", Value: "1"},
+		searchquery.Filter{Name: "x ", Value: "1"},
+		searchquery.Filter{Name: searchquery.InName, Value: "a}b"},
+		searchquery.Filter{Name: searchquery.InName, Value: `say "hi"`},
+	} {
+		check.Error(searchquery.Query{c}.Validate(), c.String())
+	}
+}
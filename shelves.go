@@ -6,10 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 )
@@ -47,64 +44,33 @@ type ShelfParams struct {
 	Books       []int       `json:"books,omitempty"`
 	Tags        []TagParams `json:"tags,omitempty"`
 	Image       string      `json:"image,omitempty"`
+	// ImageUpload is read in place of Image when set.
+	ImageUpload *Upload `json:"-"`
+
+	method string
 }
 
 func (bp ShelfParams) Form() (string, io.Reader, error) {
 
-	if bp.Image != "" {
-
-		body := bytes.NewBuffer(nil)
-		writer := multipart.NewWriter(body)
+	if bp.Image != "" || bp.ImageUpload != nil {
 
-		defer writer.Close()
+		fields := overrideFields(bp.method)
 
 		if bp.Name != "" {
-
-			if err := writer.WriteField("name", bp.Name); err != nil {
-				return "", nil, err
-			}
-
+			fields = append(fields, formField{"name", bp.Name})
 		}
 
-		for _, x := range bp.Books {
-
-			if err := writer.WriteField("books", strconv.Itoa(x)); err != nil {
-				return "", nil, err
-			}
-
+		for n, x := range bp.Books {
+			fields = append(fields, formField{fmt.Sprintf("books[%d]", n), strconv.Itoa(x)})
 		}
 
-		// TODO: Tags
+		fields = append(fields, tagFields(bp.Tags)...)
 
 		if bp.Description != "" {
-
-			if err := writer.WriteField("description", bp.Description); err != nil {
-				return "", nil, err
-			}
-
-		}
-
-		img, err := writer.CreateFormFile("image", filepath.Base(bp.Image))
-		if err != nil {
-			return "", nil, err
-		}
-
-		f, err := os.Open(bp.Image)
-		if err != nil {
-			return "", nil, err
-		}
-
-		defer f.Close()
-
-		if _, err := io.Copy(img, f); err != nil {
-			return "", nil, err
-		}
-
-		if err := writer.Close(); err != nil {
-			return "", nil, err
+			fields = append(fields, formField{"description", bp.Description})
 		}
 
-		return writer.FormDataContentType(), bytes.NewReader(body.Bytes()), nil
+		return multipartForm(fields, multipartFile{field: "image", path: bp.Image, upload: bp.ImageUpload})
 
 	}
 
@@ -117,6 +83,14 @@ func (bp ShelfParams) Form() (string, io.Reader, error) {
 
 }
 
+func (bp ShelfParams) replayable() bool {
+	return bp.ImageUpload.replayable()
+}
+
+func (bp ShelfParams) override() string {
+	return bp.method
+}
+
 // ListShelves will return the shelves that match the given params.
 func (b *Bookstack) ListShelves(ctx context.Context, params *QueryParams) ([]Shelf, error) {
 
@@ -128,6 +102,18 @@ func (b *Bookstack) ListShelves(ctx context.Context, params *QueryParams) ([]She
 	return ParseMultiple[[]Shelf](resp)
 }
 
+// ListShelvesResult will return a page of the shelves that match the given params,
+// along with the total and the pagination applied.
+func (b *Bookstack) ListShelvesResult(ctx context.Context, params *QueryParams) (ListResult[Shelf], error) {
+	return List[Shelf](ctx, b, "/shelves", params)
+}
+
+// AllShelves will return every shelf that matches the given params, following
+// pagination. The Count of params sets the page size.
+func (b *Bookstack) AllShelves(ctx context.Context, params *QueryParams) ([]Shelf, error) {
+	return Paginate[Shelf](ctx, b, "/shelves", params).All()
+}
+
 // GetShelf will return a single shelf that matches id.
 func (b *Bookstack) GetShelf(ctx context.Context, id int) (ShelfDetailed, error) {
 
@@ -154,7 +140,10 @@ func (b *Bookstack) CreateShelf(ctx context.Context, params ShelfParams) (Shelf,
 // UpdateShelf will update a shelf with the given params.
 func (b *Bookstack) UpdateShelf(ctx context.Context, id int, params ShelfParams) (Shelf, error) {
 
-	resp, err := b.request(ctx, http.MethodPut, fmt.Sprintf("/shelves/%d", id), params)
+	var method string
+	method, params.method = overrideMethod(http.MethodPut, params.Image != "" || params.ImageUpload != nil)
+
+	resp, err := b.request(ctx, method, fmt.Sprintf("/shelves/%d", id), params)
 	if err != nil {
 		return Shelf{}, err
 	}
@@ -0,0 +1,587 @@
+// Package sync keeps the pages of a BookStack book and a directory of
+// Markdown files, usually a git working tree, in agreement. The layout of
+// the directory is:
+//
+//	{page}.md
+//	{chapter}/{page}.md
+//
+// Every file starts with YAML front matter holding the id and name of its
+// page. Each run compares both sides with what they were at the last run,
+// as recorded in StateFile, and copies the side that changed to the other:
+// pages changed in BookStack are written to their files, and files that were
+// edited are sent with UpdatePage. A page that changed on both sides is
+// reported as a Conflict, and neither side is touched until they agree.
+//
+// Files without an id are created as pages, in the chapter of their
+// directory. Pages deleted in BookStack have their files removed, but
+// deleting a file does not delete its page: the page is written again,
+// which is also how a conflict is resolved in favour of BookStack.
+// Committing the changes made to the directory is left to the caller.
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/internal/localfs"
+)
+
+// StateFile is the name of the file, in the root of the directory, that
+// records what both sides were at the last run.
+const StateFile = ".bookstack-sync.json"
+
+// FrontMatter is the YAML header of every file.
+type FrontMatter struct {
+	ID   int    `yaml:"id,omitempty"`
+	Name string `yaml:"name,omitempty"`
+}
+
+// Conflict is a page that could not be synced.
+type Conflict struct {
+	// ID is zero for files that are not pages yet.
+	ID     int
+	Path   string
+	Reason string
+}
+
+// Result summarises a run.
+type Result struct {
+	// Pulled are the ids of the pages written to their files.
+	Pulled []int
+	// Pushed are the ids of the pages updated or created from their files.
+	Pushed []int
+	// Removed are the ids of the pages whose files were removed, as they
+	// were deleted in BookStack.
+	Removed   []int
+	Conflicts []Conflict
+}
+
+// Syncer syncs a book with a directory.
+type Syncer struct {
+	client *bookstack.Bookstack
+	book   int
+	dir    string
+}
+
+// New returns a syncer of the book with the given id and dir.
+func New(client *bookstack.Bookstack, book int, dir string) *Syncer {
+	return &Syncer{client: client, book: book, dir: dir}
+}
+
+type state struct {
+	Book  int               `json:"book"`
+	Pages map[int]pageState `json:"pages"`
+}
+
+type pageState struct {
+	Path          string    `json:"path"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	RevisionCount int       `json:"revision_count"`
+	// Hash is the SHA-256 of the file as it was last synced.
+	Hash string `json:"hash"`
+}
+
+// file is a Markdown file read from the directory.
+type file struct {
+	path  string
+	front FrontMatter
+	body  string
+	hash  string
+}
+
+// name returns the name of the page of f.
+func (f *file) name() string {
+
+	if f.front.Name != "" {
+		return f.front.Name
+	}
+
+	return strings.TrimSuffix(path.Base(f.path), path.Ext(f.path))
+}
+
+// run is the state of a single run of a Syncer.
+type run struct {
+	*Syncer
+	state  *state
+	result Result
+	byID   map[int]*file
+	byPath map[string]*file
+	// dirs maps the ids of the chapters of the book to their directories.
+	dirs map[int]string
+}
+
+// Run will sync the book and the directory once. Conflicts are reported in
+// the result rather than as an error. The state is saved even when Run
+// fails, so that the changes already made are not synced again.
+func (s *Syncer) Run(ctx context.Context) (result Result, err error) {
+
+	st, err := s.loadState()
+	if err != nil {
+		return result, err
+	}
+
+	if st.Book != 0 && st.Book != s.book {
+		return result, fmt.Errorf("sync: %s is synced with book %d", s.dir, st.Book)
+	}
+
+	st.Book = s.book
+
+	r := &run{
+		Syncer: s,
+		state:  st,
+		result: Result{Pulled: []int{}, Pushed: []int{}, Removed: []int{}, Conflicts: []Conflict{}},
+		byID:   map[int]*file{},
+		byPath: map[string]*file{},
+		dirs:   map[int]string{},
+	}
+
+	defer func() {
+
+		result = r.result
+
+		if saveErr := s.saveState(st); err == nil {
+			err = saveErr
+		}
+	}()
+
+	files, err := s.readFiles()
+	if err != nil {
+		return result, err
+	}
+
+	for _, f := range files {
+
+		if other, ok := r.byID[f.front.ID]; ok && f.front.ID != 0 {
+			return result, fmt.Errorf("sync: %s and %s are both page %d", other.path, f.path, f.front.ID)
+		}
+
+		if f.front.ID != 0 {
+			r.byID[f.front.ID] = f
+		}
+
+		r.byPath[f.path] = f
+	}
+
+	filter := &bookstack.QueryParams{FilterField: "book_id", FilterValue: strconv.Itoa(s.book)}
+
+	chapters, err := s.client.AllChapters(ctx, filter)
+	if err != nil {
+		return result, err
+	}
+
+	for _, c := range chapters {
+		r.dirs[c.ID] = localfs.Segment(c.Slug, c.ID)
+	}
+
+	pages, err := s.client.AllPages(ctx, filter)
+	if err != nil {
+		return result, err
+	}
+
+	remote := map[int]bool{}
+
+	for _, page := range pages {
+
+		remote[page.ID] = true
+
+		if err := r.page(ctx, page); err != nil {
+			return result, fmt.Errorf("sync: page %d: %w", page.ID, err)
+		}
+	}
+
+	ids := []int{}
+	for id := range st.Pages {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		if !remote[id] {
+			r.deleted(id)
+		}
+	}
+
+	for _, f := range files {
+
+		if r.byPath[f.path] != f {
+			continue
+		}
+
+		if f.front.ID == 0 {
+
+			if err := r.create(ctx, f); err != nil {
+				return result, fmt.Errorf("sync: %s: %w", f.path, err)
+			}
+
+		} else if _, ok := st.Pages[f.front.ID]; !ok && !remote[f.front.ID] {
+			r.conflict(f.front.ID, f.path, "the page is not in the book")
+		}
+	}
+
+	return result, nil
+}
+
+// page syncs a page of the book with its file.
+func (r *run) page(ctx context.Context, page bookstack.Page) error {
+
+	last, known := r.state.Pages[page.ID]
+	f := r.byID[page.ID]
+
+	switch {
+	case f == nil:
+		return r.pull(ctx, page.ID, nil)
+
+	case !known:
+		// The file was written by hand, or the state was lost, so it is only
+		// kept when it matches the page.
+		return r.pullIfSame(ctx, page.ID, f, "the file was not synced before")
+	}
+
+	remoteChanged := !page.UpdatedAt.Equal(last.UpdatedAt) || page.RevisionCount != last.RevisionCount
+	localChanged := f.hash != last.Hash
+
+	switch {
+	case remoteChanged && localChanged:
+		return r.pullIfSame(ctx, page.ID, f, "the page and the file both changed")
+
+	case remoteChanged:
+		return r.pull(ctx, page.ID, f)
+
+	case localChanged:
+		return r.push(ctx, page.ID, f)
+	}
+
+	// Renaming a chapter moves its pages without updating them.
+	return r.move(page.ID, f, r.pagePath(page.ChapterID, page.Slug, page.ID))
+}
+
+// pull writes the page with the given id to its file, replacing f.
+func (r *run) pull(ctx context.Context, id int, f *file) error {
+
+	page, content, err := r.fetch(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	target := r.pagePath(page.ChapterID, page.Slug, page.ID)
+
+	if other, ok := r.byPath[target]; ok && other != f {
+		r.conflict(id, target, "the file of another page is in the way")
+		return nil
+	}
+
+	if err := r.writeFile(target, content); err != nil {
+		return err
+	}
+
+	if f != nil && f.path != target {
+		r.remove(f.path)
+	}
+
+	r.state.Pages[id] = pageState{Path: target, UpdatedAt: page.UpdatedAt, RevisionCount: page.RevisionCount, Hash: hash(content)}
+	r.result.Pulled = append(r.result.Pulled, id)
+
+	return nil
+}
+
+// pullIfSame records f as synced when it matches the page with the given id,
+// and reports a conflict for the reason given otherwise.
+func (r *run) pullIfSame(ctx context.Context, id int, f *file, reason string) error {
+
+	page, content, err := r.fetch(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if hash(content) != f.hash {
+		r.conflict(id, f.path, reason)
+		return nil
+	}
+
+	r.state.Pages[id] = pageState{Path: f.path, UpdatedAt: page.UpdatedAt, RevisionCount: page.RevisionCount, Hash: f.hash}
+
+	return r.move(id, f, r.pagePath(page.ChapterID, page.Slug, page.ID))
+}
+
+// push updates the page with the given id from f.
+func (r *run) push(ctx context.Context, id int, f *file) error {
+
+	page, err := r.client.UpdatePage(ctx, id, bookstack.PageParams{Name: f.name(), Markdown: f.body})
+	if err != nil {
+		return err
+	}
+
+	r.state.Pages[id] = pageState{Path: f.path, UpdatedAt: page.UpdatedAt, RevisionCount: page.RevisionCount, Hash: f.hash}
+	r.result.Pushed = append(r.result.Pushed, id)
+
+	return r.move(id, f, r.pagePath(page.ChapterID, page.Slug, page.ID))
+}
+
+// create creates a page from f, in the chapter of its directory, and writes
+// its id into f.
+func (r *run) create(ctx context.Context, f *file) error {
+
+	params := bookstack.PageParams{Name: f.name(), Markdown: f.body}
+
+	if dir := path.Dir(f.path); dir == "." {
+		params.BookID = r.book
+	} else {
+
+		for id, d := range r.dirs {
+			if d == dir {
+				params.ChapterID = id
+			}
+		}
+
+		if params.ChapterID == 0 {
+			r.conflict(0, f.path, "the directory is not a chapter of the book")
+			return nil
+		}
+	}
+
+	page, err := r.client.CreatePage(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	content, err := render(FrontMatter{ID: page.ID, Name: page.Name}, f.body)
+	if err != nil {
+		return err
+	}
+
+	target := r.pagePath(page.ChapterID, page.Slug, page.ID)
+
+	if _, ok := r.byPath[target]; ok && target != f.path {
+		target = f.path
+	}
+
+	if err := r.writeFile(target, content); err != nil {
+		return err
+	}
+
+	if target != f.path {
+		r.remove(f.path)
+	}
+
+	r.state.Pages[page.ID] = pageState{Path: target, UpdatedAt: page.UpdatedAt, RevisionCount: page.RevisionCount, Hash: hash(content)}
+	r.result.Pushed = append(r.result.Pushed, page.ID)
+
+	return nil
+}
+
+// deleted removes the file of a page that was deleted in BookStack, unless
+// the file was changed since.
+func (r *run) deleted(id int) {
+
+	last := r.state.Pages[id]
+	f := r.byID[id]
+
+	switch {
+	case f == nil:
+		delete(r.state.Pages, id)
+
+	case f.hash == last.Hash:
+		r.remove(f.path)
+		delete(r.byPath, f.path)
+		delete(r.state.Pages, id)
+		r.result.Removed = append(r.result.Removed, id)
+
+	default:
+		r.conflict(id, f.path, "the file changed and the page was deleted")
+	}
+}
+
+// move moves f to target, unless another file is already there.
+func (r *run) move(id int, f *file, target string) error {
+
+	if f.path == target {
+		return nil
+	}
+
+	if _, ok := r.byPath[target]; ok {
+		return nil
+	}
+
+	to := filepath.Join(r.dir, filepath.FromSlash(target))
+
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(filepath.Join(r.dir, filepath.FromSlash(f.path)), to); err != nil {
+		return err
+	}
+
+	localfs.RemoveDirs(r.dir, f.path)
+
+	delete(r.byPath, f.path)
+	f.path = target
+	r.byPath[target] = f
+
+	last := r.state.Pages[id]
+	last.Path = target
+	r.state.Pages[id] = last
+
+	return nil
+}
+
+func (r *run) conflict(id int, p, reason string) {
+	r.result.Conflicts = append(r.result.Conflicts, Conflict{ID: id, Path: p, Reason: reason})
+}
+
+// fetch returns a page and the content of its file.
+func (r *run) fetch(ctx context.Context, id int) (bookstack.PageDetailed, []byte, error) {
+
+	page, err := r.client.GetPage(ctx, id)
+	if err != nil {
+		return page, nil, err
+	}
+
+	body := page.Markdown
+
+	// Pages written in the WYSIWYG editor have no Markdown of their own.
+	if body == "" && page.HTML != "" {
+
+		export, err := r.client.ExportPageMarkdown(ctx, id)
+		if err != nil {
+			return page, nil, err
+		}
+
+		raw, err := io.ReadAll(export)
+		if err != nil {
+			return page, nil, err
+		}
+
+		body = string(raw)
+	}
+
+	content, err := render(FrontMatter{ID: page.ID, Name: page.Name}, body)
+
+	return page, content, err
+}
+
+// pagePath returns where the file of a page belongs.
+func (r *run) pagePath(chapter int, slug string, id int) string {
+
+	name := localfs.Segment(slug, id) + ".md"
+
+	if dir, ok := r.dirs[chapter]; ok && chapter != 0 {
+		return path.Join(dir, name)
+	}
+
+	return name
+}
+
+func (s *Syncer) readFiles() ([]*file, error) {
+
+	files := []*file{}
+
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+
+		if err != nil {
+			return err
+		}
+
+		if p != s.dir && strings.HasPrefix(d.Name(), ".") {
+
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(p), ".md") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+
+		f, err := readFile(p, filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+
+		files = append(files, f)
+
+		return nil
+	})
+
+	if errors.Is(err, os.ErrNotExist) {
+		return files, nil
+	}
+
+	return files, err
+}
+
+func readFile(p, rel string) (*file, error) {
+
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &file{path: rel, hash: hash(raw)}
+
+	f.body, err = localfs.ParseFrontMatter(raw, &f.front)
+	if err != nil {
+		return nil, fmt.Errorf("sync: %s: %w", rel, err)
+	}
+
+	return f, nil
+}
+
+// render returns the content of the file of a page.
+func render(front FrontMatter, body string) ([]byte, error) {
+	return localfs.Render(front, strings.TrimSpace(body)+"\n")
+}
+
+func (s *Syncer) writeFile(target string, content []byte) error {
+	return localfs.WriteFile(s.dir, target, content)
+}
+
+// remove removes the file at target, and any directories left empty by it.
+func (s *Syncer) remove(target string) {
+	localfs.Remove(s.dir, target)
+}
+
+func (s *Syncer) loadState() (*state, error) {
+
+	st := &state{Pages: map[int]pageState{}}
+
+	if err := localfs.LoadState(s.dir, StateFile, st); err != nil {
+		return nil, fmt.Errorf("sync: reading %s: %w", StateFile, err)
+	}
+
+	if st.Pages == nil {
+		st.Pages = map[int]pageState{}
+	}
+
+	return st, nil
+}
+
+func (s *Syncer) saveState(st *state) error {
+	return localfs.SaveState(s.dir, StateFile, st)
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
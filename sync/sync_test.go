@@ -0,0 +1,157 @@
+package sync_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/hcarriz/go-bookstack/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func readFile(t *testing.T, dir, name string) string {
+
+	raw, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	require.NoError(t, err)
+
+	return string(raw)
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+
+	p := filepath.Join(dir, filepath.FromSlash(name))
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+}
+
+func TestSync(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	dir := t.TempDir()
+
+	// Files in the git directory are not pages.
+	writeFile(t, dir, ".git/info/notes.md", "Not a page.")
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Guide"})
+	check.NoError(err)
+
+	chapter, err := bk.CreateChapter(ctx, bookstack.ChapterParams{BookID: book.ID, Name: "Basics"})
+	check.NoError(err)
+
+	intro, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Intro", Markdown: "Hello."})
+	check.NoError(err)
+
+	start, err := bk.CreatePage(ctx, bookstack.PageParams{ChapterID: chapter.ID, Name: "Start", Markdown: "Starting."})
+	check.NoError(err)
+
+	s := sync.New(bk, book.ID, dir)
+
+	result, err := s.Run(ctx)
+	check.NoError(err)
+	check.Equal([]int{intro.ID, start.ID}, result.Pulled)
+	check.Empty(result.Pushed)
+	check.Empty(result.Conflicts)
+
+	check.Equal("---\nid: "+strconv.Itoa(intro.ID)+"\nname: Intro\n---\n\nHello.\n", readFile(t, dir, "intro.md"))
+	check.Contains(readFile(t, dir, "basics/start.md"), "Starting.")
+
+	result, err = s.Run(ctx)
+	check.NoError(err)
+	check.Empty(result.Pulled)
+	check.Empty(result.Pushed)
+
+	writeFile(t, dir, "intro.md", strings.Replace(readFile(t, dir, "intro.md"), "Hello.", "Hello, world.", 1))
+
+	_, err = bk.UpdatePage(ctx, start.ID, bookstack.PageParams{Markdown: "Starting again."})
+	check.NoError(err)
+
+	result, err = s.Run(ctx)
+	check.NoError(err)
+	check.Equal([]int{intro.ID}, result.Pushed)
+	check.Equal([]int{start.ID}, result.Pulled)
+
+	page, err := bk.GetPage(ctx, intro.ID)
+	check.NoError(err)
+	check.Equal("Hello, world.", page.Markdown)
+	check.Contains(readFile(t, dir, "basics/start.md"), "Starting again.")
+
+	result, err = s.Run(ctx)
+	check.NoError(err)
+	check.Empty(result.Pulled)
+	check.Empty(result.Pushed)
+
+	local := strings.Replace(readFile(t, dir, "intro.md"), "Hello, world.", "Local edit.", 1)
+	writeFile(t, dir, "intro.md", local)
+
+	_, err = bk.UpdatePage(ctx, intro.ID, bookstack.PageParams{Markdown: "Remote edit."})
+	check.NoError(err)
+
+	for i := 0; i < 2; i++ {
+
+		result, err = s.Run(ctx)
+		check.NoError(err)
+		check.Equal([]sync.Conflict{{ID: intro.ID, Path: "intro.md", Reason: "the page and the file both changed"}}, result.Conflicts)
+		check.Empty(result.Pulled)
+		check.Empty(result.Pushed)
+		check.Equal(local, readFile(t, dir, "intro.md"))
+
+		page, err = bk.GetPage(ctx, intro.ID)
+		check.NoError(err)
+		check.Equal("Remote edit.", page.Markdown)
+	}
+
+	// Deleting the file takes the page as it is in BookStack.
+	check.NoError(os.Remove(filepath.Join(dir, "intro.md")))
+
+	result, err = s.Run(ctx)
+	check.NoError(err)
+	check.Empty(result.Conflicts)
+	check.Equal([]int{intro.ID}, result.Pulled)
+	check.Contains(readFile(t, dir, "intro.md"), "Remote edit.")
+
+	writeFile(t, dir, "basics/Next Steps.md", "Then this.")
+	writeFile(t, dir, "elsewhere/lost.md", "Nowhere to go.")
+
+	result, err = s.Run(ctx)
+	check.NoError(err)
+	check.Len(result.Pushed, 1)
+	check.Equal([]sync.Conflict{{Path: "elsewhere/lost.md", Reason: "the directory is not a chapter of the book"}}, result.Conflicts)
+
+	next, err := bk.GetPage(ctx, result.Pushed[0])
+	check.NoError(err)
+	check.Equal("Next Steps", next.Name)
+	check.Equal(chapter.ID, next.ChapterID)
+	check.Equal("Then this.", next.Markdown)
+	check.NoFileExists(filepath.Join(dir, "basics/Next Steps.md"))
+	check.Contains(readFile(t, dir, "basics/next-steps.md"), "id: "+strconv.Itoa(next.ID))
+
+	check.NoError(os.RemoveAll(filepath.Join(dir, "elsewhere")))
+
+	_, err = bk.DeletePage(ctx, start.ID)
+	check.NoError(err)
+
+	result, err = s.Run(ctx)
+	check.NoError(err)
+	check.Equal([]int{start.ID}, result.Removed)
+	check.Empty(result.Pushed)
+	check.Empty(result.Conflicts)
+	check.NoFileExists(filepath.Join(dir, "basics/start.md"))
+	check.FileExists(filepath.Join(dir, "basics/next-steps.md"))
+
+	_, err = sync.New(bk, book.ID+1, dir).Run(ctx)
+	check.Error(err)
+}
@@ -0,0 +1,337 @@
+package bookstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type SystemInfo struct {
+	Version    string `json:"version,omitempty"`
+	InstanceID string `json:"instance_id,omitempty"`
+	AppName    string `json:"app_name,omitempty"`
+	BaseURL    string `json:"base_url,omitempty"`
+}
+
+// GetSystemInfo will return the version and details of the connected
+// instance. Servers older than v24.05 do not provide it, and return an error
+// matching ErrNotFound.
+func (b *Bookstack) GetSystemInfo(ctx context.Context) (SystemInfo, error) {
+
+	resp, err := b.request(ctx, http.MethodGet, "/system", blank{})
+	if err != nil {
+		return SystemInfo{}, err
+	}
+
+	return ParseSingle[SystemInfo](resp)
+}
+
+// Version is a BookStack release, such as v23.05.2.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses a BookStack version such as "v23.05" or "v23.05.2".
+// Any suffix after the numbers, such as "-dev", is ignored.
+func ParseVersion(s string) (Version, error) {
+
+	v := Version{}
+
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "v")
+
+	if i := strings.IndexFunc(trimmed, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+
+	parts := strings.Split(trimmed, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return v, fmt.Errorf("bookstack: invalid version %q", s)
+	}
+
+	fields := []*int{&v.Major, &v.Minor, &v.Patch}
+
+	for i, p := range parts {
+
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return v, fmt.Errorf("bookstack: invalid version %q", s)
+		}
+
+		*fields[i] = n
+	}
+
+	return v, nil
+}
+
+func (v Version) String() string {
+
+	if v.Patch == 0 {
+		return fmt.Sprintf("v%d.%02d", v.Major, v.Minor)
+	}
+
+	return fmt.Sprintf("v%d.%02d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is the same release as o or a later one.
+func (v Version) AtLeast(o Version) bool {
+
+	if v.Major != o.Major {
+		return v.Major > o.Major
+	}
+
+	if v.Minor != o.Minor {
+		return v.Minor > o.Minor
+	}
+
+	return v.Patch >= o.Patch
+}
+
+// Capability is a part of the API that is only available on newer servers.
+type Capability string
+
+const (
+	CapabilityAuditLog           Capability = "audit log"
+	CapabilityContentPermissions Capability = "content permissions"
+	CapabilityImageGallery       Capability = "image gallery"
+	CapabilityImports            Capability = "imports"
+	CapabilityRoles              Capability = "roles"
+	CapabilitySystemInfo         Capability = "system info"
+	CapabilityZipExport          Capability = "zip export"
+)
+
+// systemInfoVersion is the release that added the system endpoint. Servers
+// without it are older, but their exact version is unknown.
+var systemInfoVersion = Version{Major: 24, Minor: 5}
+
+// capabilities maps each capability to the release that added it.
+var capabilities = map[Capability]Version{
+	CapabilityAuditLog:           {Major: 21, Minor: 8},
+	CapabilityContentPermissions: {Major: 23, Minor: 1},
+	CapabilityImageGallery:       {Major: 23, Minor: 2},
+	CapabilityImports:            {Major: 25, Minor: 2},
+	CapabilityRoles:              {Major: 23, Minor: 5},
+	CapabilitySystemInfo:         systemInfoVersion,
+	CapabilityZipExport:          {Major: 25, Minor: 2},
+}
+
+// MinimumVersion returns the release that added c.
+func MinimumVersion(c Capability) (Version, bool) {
+	v, ok := capabilities[c]
+	return v, ok
+}
+
+// SetServerVersion sets the version of the server instead of detecting it
+// through GetSystemInfo.
+func SetServerVersion(version string) Option {
+	return func(b *Bookstack) {
+		b.server.pin(version)
+	}
+}
+
+// serverVersion is the detected version of the server, shared by every
+// request of a client.
+type serverVersion struct {
+	mu    sync.Mutex
+	state versionState
+	// probed holds, for servers too old to report their version, whether
+	// each capability was found by probing its endpoint.
+	probed map[Capability]bool
+}
+
+type versionState struct {
+	// known is set once the version is detected, or found to be older than
+	// the system endpoint.
+	known   bool
+	version Version
+	// parsed is false when the server reported a version that could not be
+	// parsed, in which case every capability is assumed to be supported.
+	parsed bool
+	// legacy is set when the server is older than the system endpoint.
+	legacy bool
+}
+
+func (s *serverVersion) pin(version string) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, err := ParseVersion(version)
+
+	s.state = versionState{known: true, version: v, parsed: err == nil}
+}
+
+// detect returns the state of the server version, calling GetSystemInfo on
+// first use. The request is made without holding the lock, so calls made
+// at the same time may each detect the version, and the first to finish is
+// kept.
+func (b *Bookstack) detect(ctx context.Context) (versionState, error) {
+
+	s := &b.server
+
+	s.mu.Lock()
+	state := s.state
+	s.mu.Unlock()
+
+	if state.known {
+		return state, nil
+	}
+
+	info, err := b.GetSystemInfo(ctx)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		state = versionState{known: true, legacy: true}
+	case err != nil:
+		return versionState{}, err
+	default:
+		v, err := ParseVersion(info.Version)
+		state = versionState{known: true, version: v, parsed: err == nil}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.state.known {
+		s.state = state
+	}
+
+	return s.state, nil
+}
+
+// ServerVersion returns the version of the connected server, detecting it
+// on first use. The result is cached for the lifetime of the client. For
+// servers older than v24.05 the version cannot be detected, and ok is false.
+func (b *Bookstack) ServerVersion(ctx context.Context) (version Version, ok bool, err error) {
+
+	state, err := b.detect(ctx)
+	if err != nil {
+		return Version{}, false, err
+	}
+
+	return state.version, state.parsed, nil
+}
+
+// Supports reports whether the connected server provides c. On servers too
+// old to report their version, capabilities added before v24.05 are found by
+// requesting their endpoint once, and the result is cached for the lifetime
+// of the client.
+func (b *Bookstack) Supports(ctx context.Context, c Capability) (bool, error) {
+
+	added, ok := capabilities[c]
+	if !ok {
+		return false, fmt.Errorf("bookstack: unknown capability %q", c)
+	}
+
+	state, err := b.detect(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case state.legacy && added.AtLeast(systemInfoVersion):
+		return false, nil
+	case state.legacy:
+		return b.probe(ctx, c)
+	case state.parsed:
+		return state.version.AtLeast(added), nil
+	default:
+		return true, nil
+	}
+}
+
+// probes request the endpoint of a capability, failing with ErrNotFound
+// when the server does not have it.
+var probes = map[Capability]func(ctx context.Context, b *Bookstack) error{
+	CapabilityAuditLog:           probeList("/audit-log"),
+	CapabilityContentPermissions: probeContentPermissions,
+	CapabilityImageGallery:       probeList("/image-gallery"),
+	CapabilityRoles:              probeList("/roles"),
+}
+
+func probeList(path string) func(ctx context.Context, b *Bookstack) error {
+	return func(ctx context.Context, b *Bookstack) error {
+		_, err := b.request(ctx, http.MethodGet, (&QueryParams{Count: 1}).String(path), blank{})
+		return err
+	}
+}
+
+// probeContentPermissions reads the permissions of a book, as there is no
+// list endpoint. An instance without books is assumed to have the endpoint.
+func probeContentPermissions(ctx context.Context, b *Bookstack) error {
+
+	books, err := b.ListBooks(ctx, &QueryParams{Count: 1})
+	if err != nil || len(books) == 0 {
+		return err
+	}
+
+	_, err = b.request(ctx, http.MethodGet, fmt.Sprintf("/content-permissions/%s/%d", ContentBook, books[0].ID), blank{})
+	return err
+}
+
+// probe reports whether a server too old to report its version provides c,
+// requesting its endpoint on first use. Errors other than ErrNotFound, such
+// as a lack of permission, still show that the endpoint exists. As with
+// detect, the request is made without holding the lock.
+func (b *Bookstack) probe(ctx context.Context, c Capability) (bool, error) {
+
+	s := &b.server
+
+	s.mu.Lock()
+	ok, found := s.probed[c]
+	s.mu.Unlock()
+
+	if found {
+		return ok, nil
+	}
+
+	fn, ok := probes[c]
+	if !ok {
+		return true, nil
+	}
+
+	err := fn(ctx, b)
+
+	var apiErr *APIError
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		ok = false
+	case err == nil || errors.As(err, &apiErr):
+		ok = true
+	default:
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.probed == nil {
+		s.probed = map[Capability]bool{}
+	}
+
+	s.probed[c] = ok
+
+	return ok, nil
+}
+
+// require returns an error matching ErrUnsupported when the connected server
+// does not provide c.
+func (b *Bookstack) require(ctx context.Context, c Capability) error {
+
+	ok, err := b.Supports(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %s requires BookStack %s or later", ErrUnsupported, c, capabilities[c])
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package bookstack_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+
+	tests := []struct {
+		in   string
+		want bookstack.Version
+		err  bool
+	}{
+		{in: "v23.05", want: bookstack.Version{Major: 23, Minor: 5}},
+		{in: "v23.05.2", want: bookstack.Version{Major: 23, Minor: 5, Patch: 2}},
+		{in: "24.10-dev", want: bookstack.Version{Major: 24, Minor: 10}},
+		{in: "v23", err: true},
+		{in: "latest", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+
+			check := require.New(t)
+
+			got, err := bookstack.ParseVersion(tt.in)
+
+			if tt.err {
+				check.Error(err)
+				return
+			}
+
+			check.NoError(err)
+			check.Equal(tt.want, got)
+		})
+	}
+
+	check := require.New(t)
+
+	check.Equal("v23.05.2", bookstack.Version{Major: 23, Minor: 5, Patch: 2}.String())
+	check.True(bookstack.Version{Major: 23, Minor: 5}.AtLeast(bookstack.Version{Major: 23, Minor: 5}))
+	check.False(bookstack.Version{Major: 23, Minor: 4, Patch: 9}.AtLeast(bookstack.Version{Major: 23, Minor: 5}))
+}
+
+func TestSystemInfo(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	info, err := bk.GetSystemInfo(ctx)
+	check.NoError(err)
+	check.Equal(bookstacktest.DefaultVersion, info.Version)
+	check.Equal("BookStack", info.AppName)
+	check.Equal(srv.URL, info.BaseURL)
+	check.NotEmpty(info.InstanceID)
+
+	version, ok, err := bk.ServerVersion(ctx)
+	check.NoError(err)
+	check.True(ok)
+	check.Equal(bookstacktest.DefaultVersion, version.String())
+
+	// The detected version is cached for the lifetime of the client.
+	srv.SetVersion("v23.01")
+
+	_, err = bk.ListRoles(ctx, nil)
+	check.NoError(err)
+
+	_, err = srv.Client().ListRoles(ctx, nil)
+	check.ErrorIs(err, bookstack.ErrUnsupported)
+
+	ok, err = srv.Client().Supports(ctx, bookstack.CapabilityContentPermissions)
+	check.NoError(err)
+	check.True(ok)
+}
+
+func TestSystemInfoLegacy(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	srv.SetVersion("")
+
+	bk := srv.Client()
+
+	_, err := bk.GetSystemInfo(ctx)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+
+	_, ok, err := bk.ServerVersion(ctx)
+	check.NoError(err)
+	check.False(ok)
+
+	// Capabilities older than the system endpoint are found by requesting
+	// their endpoint.
+	_, err = bk.ListRoles(ctx, nil)
+	check.NoError(err)
+
+	ok, err = bk.Supports(ctx, bookstack.CapabilityContentPermissions)
+	check.NoError(err)
+	check.True(ok)
+
+	srv.AddBook(bookstack.BookDetailed{Name: "Guide"})
+
+	old := srv.Client()
+
+	srv.RemoveEndpoints("roles", "content-permissions")
+
+	_, err = old.ListRoles(ctx, nil)
+	check.ErrorIs(err, bookstack.ErrUnsupported)
+
+	_, err = old.GetContentPermissions(ctx, bookstack.ContentBook, 1)
+	check.ErrorIs(err, bookstack.ErrUnsupported)
+
+	// The result is cached for the lifetime of the client.
+	_, err = bk.ListRoles(ctx, nil)
+	check.ErrorIs(err, bookstack.ErrNotFound)
+	check.NotErrorIs(err, bookstack.ErrUnsupported)
+
+	_, err = old.ListAuditLog(ctx, nil)
+	check.NoError(err)
+
+	ok, err = bk.Supports(ctx, bookstack.CapabilitySystemInfo)
+	check.NoError(err)
+	check.False(ok)
+
+	_, err = bk.Supports(ctx, bookstack.Capability("time travel"))
+	check.Error(err)
+}
+
+func TestSetServerVersion(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client(bookstack.SetServerVersion("v22.11"))
+
+	_, err := bk.GetImage(ctx, 1)
+	check.ErrorIs(err, bookstack.ErrUnsupported)
+	check.Contains(err.Error(), "v23.02")
+
+	_, err = bk.ListAuditLog(ctx, nil)
+	check.NoError(err)
+}
@@ -0,0 +1,69 @@
+package bookstack_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	tags := []bookstack.TagParams{{Name: "team", Value: "ops"}, {Name: "draft"}}
+
+	want := []bookstack.Tag{{Name: "team", Value: "ops"}, {Name: "draft"}}
+
+	names := func(got []bookstack.Tag) []bookstack.Tag {
+
+		list := []bookstack.Tag{}
+
+		for _, t := range got {
+			list = append(list, bookstack.Tag{Name: t.Name, Value: t.Value})
+		}
+
+		return list
+	}
+
+	for _, image := range []string{"", "./test_data/upload.png"} {
+
+		book, err := bk.CreateBook(ctx, bookstack.BookParams{Name: "Book", Tags: tags, Image: image})
+		check.NoError(err)
+
+		detailed, err := bk.GetBook(ctx, book.ID)
+		check.NoError(err)
+		check.Equal(want, names(detailed.Tags), "image %q", image)
+
+		_, err = bk.UpdateBook(ctx, book.ID, bookstack.BookParams{Tags: tags[:1], Image: image})
+		check.NoError(err)
+
+		detailed, err = bk.GetBook(ctx, book.ID)
+		check.NoError(err)
+		check.Equal(want[:1], names(detailed.Tags), "image %q", image)
+
+		shelf, err := bk.CreateShelf(ctx, bookstack.ShelfParams{Name: "Shelf", Books: []int{book.ID}, Tags: tags, Image: image})
+		check.NoError(err)
+
+		shelfDetailed, err := bk.GetShelf(ctx, shelf.ID)
+		check.NoError(err)
+		check.Equal(want, names(shelfDetailed.Tags), "image %q", image)
+		check.Len(shelfDetailed.Books, 1)
+
+		_, err = bk.UpdateShelf(ctx, shelf.ID, bookstack.ShelfParams{Tags: tags[1:], Image: image})
+		check.NoError(err)
+
+		shelfDetailed, err = bk.GetShelf(ctx, shelf.ID)
+		check.NoError(err)
+		check.Equal(want[1:], names(shelfDetailed.Tags), "image %q", image)
+	}
+}
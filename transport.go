@@ -0,0 +1,97 @@
+package bookstack
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+)
+
+// SetHTTPClient sets the client used to send requests. The client is copied,
+// so later changes to it have no effect.
+func SetHTTPClient(c *http.Client) Option {
+	return func(b *Bookstack) {
+		b.client = c
+	}
+}
+
+// SetTransport sets the round tripper used to send requests, replacing the
+// transport of the HTTP client.
+func SetTransport(rt http.RoundTripper) Option {
+	return func(b *Bookstack) {
+		b.transport = rt
+	}
+}
+
+// SetInsecure disables verification of the server's certificate.
+func SetInsecure(insecure bool) Option {
+	return func(b *Bookstack) {
+		b.tls = append(b.tls, func(c *tls.Config) {
+			c.InsecureSkipVerify = insecure
+		})
+	}
+}
+
+// SetRootCAs sets the certificate authorities used to verify the server.
+func SetRootCAs(pool *x509.CertPool) Option {
+	return func(b *Bookstack) {
+		b.tls = append(b.tls, func(c *tls.Config) {
+			c.RootCAs = pool
+		})
+	}
+}
+
+// SetClientCertificates sets the certificates presented to servers that
+// require mutual TLS.
+func SetClientCertificates(certs ...tls.Certificate) Option {
+	return func(b *Bookstack) {
+		b.tls = append(b.tls, func(c *tls.Config) {
+			c.Certificates = certs
+		})
+	}
+}
+
+// httpClient builds the client used for requests from the options. TLS
+// options are applied to a copy of the transport, so neither the given
+// client nor http.DefaultTransport are modified.
+func (b *Bookstack) httpClient() *http.Client {
+
+	client := &http.Client{}
+
+	if b.client != nil {
+		c := *b.client
+		client = &c
+	}
+
+	if b.transport != nil {
+		client.Transport = b.transport
+	}
+
+	if len(b.tls) == 0 {
+		return client
+	}
+
+	rt := client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		b.log.Printf("bookstack: TLS options ignored, transport %T is not an *http.Transport", rt)
+		return client
+	}
+
+	t = t.Clone()
+
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+
+	for _, opt := range b.tls {
+		opt(t.TLSClientConfig)
+	}
+
+	client.Transport = t
+
+	return client
+}
@@ -0,0 +1,72 @@
+package bookstack_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+type countingTransport struct {
+	calls int32
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestTransport(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	fake := bookstacktest.NewServer()
+	defer fake.Close()
+
+	srv := httptest.NewUnstartedServer(fake)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	token := bookstack.SetToken(bookstacktest.TokenID, bookstacktest.TokenSecret)
+	cert := bookstack.SetClientCertificates(srv.TLS.Certificates[0])
+
+	defaultTransport := http.DefaultClient.Transport
+
+	_, err := bookstack.New(bookstack.SetURL(srv.URL), token, cert).ListBooks(ctx, nil)
+	check.Error(err, "untrusted certificates are rejected")
+
+	_, err = bookstack.New(bookstack.SetURL(srv.URL), token, cert, bookstack.SetInsecure(true)).ListBooks(ctx, nil)
+	check.NoError(err)
+	check.Equal(defaultTransport, http.DefaultClient.Transport, "the default client is left alone")
+
+	_, err = bookstack.New(bookstack.SetURL(srv.URL), token, bookstack.SetInsecure(true)).ListBooks(ctx, nil)
+	check.Error(err, "the server requires a client certificate")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	_, err = bookstack.New(bookstack.SetURL(srv.URL), token, cert, bookstack.SetRootCAs(pool)).ListBooks(ctx, nil)
+	check.NoError(err)
+
+	counter := &countingTransport{}
+
+	_, err = fake.Client(bookstack.SetTransport(counter)).ListBooks(ctx, nil)
+	check.NoError(err)
+	check.Equal(int32(1), atomic.LoadInt32(&counter.calls))
+
+	client := &http.Client{Transport: counter}
+
+	_, err = fake.Client(bookstack.SetHTTPClient(client)).ListBooks(ctx, nil)
+	check.NoError(err)
+	check.Equal(int32(2), atomic.LoadInt32(&counter.calls))
+}
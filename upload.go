@@ -0,0 +1,257 @@
+package bookstack
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// errUploadConsumed is returned when an Upload that cannot be rewound is
+// sent a second time.
+var errUploadConsumed = errors.New("bookstack: upload reader has already been read and cannot be rewound")
+
+// Upload is a file to send from a reader rather than from a path on disk.
+// When Reader is an io.Seeker the request can be retried, rewinding Reader
+// to where it was first read from. Otherwise the request is sent only once.
+type Upload struct {
+	// Name is the file name sent to the server.
+	Name   string
+	Reader io.Reader
+	// Size is the number of bytes Reader will return. When zero, it is found
+	// from readers with a Len or Stat method, such as *bytes.Reader and
+	// *os.File, and otherwise the body is sent without a Content-Length.
+	Size int64
+
+	start   int64
+	started bool
+}
+
+// open readies the reader to be sent, and returns its size, or -1 when it is
+// not known.
+func (u *Upload) open() (io.Reader, int64, error) {
+
+	seeker, canSeek := u.Reader.(io.Seeker)
+
+	switch {
+	case !u.started:
+
+		u.started = true
+
+		if canSeek {
+
+			start, err := seeker.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			u.start = start
+		}
+
+	case canSeek:
+
+		if _, err := seeker.Seek(u.start, io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+
+	default:
+		return nil, 0, errUploadConsumed
+	}
+
+	return u.Reader, u.size(), nil
+}
+
+func (u *Upload) size() int64 {
+
+	if u.Size > 0 {
+		return u.Size
+	}
+
+	switch r := u.Reader.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case interface{ Stat() (os.FileInfo, error) }:
+		if info, err := r.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size() - u.start
+		}
+	}
+
+	return -1
+}
+
+// replayable reports whether u can be sent more than once.
+func (u *Upload) replayable() bool {
+
+	if u == nil {
+		return true
+	}
+
+	_, ok := u.Reader.(io.Seeker)
+
+	return ok
+}
+
+// replayable reports whether the body of data can be sent more than once.
+func replayable(data Form) bool {
+
+	r, ok := data.(interface{ replayable() bool })
+
+	return !ok || r.replayable()
+}
+
+// overrideMethod returns the method to send a request with, and the method
+// to override it with, if any. PHP only parses multipart bodies on POST, so
+// multipart requests with other methods are sent as a POST with the method
+// overridden.
+func overrideMethod(method string, multipart bool) (string, string) {
+
+	if multipart && method != http.MethodPost {
+		return http.MethodPost, method
+	}
+
+	return method, ""
+}
+
+// overrideFields returns the fields that override the method of a multipart
+// form, if any.
+func overrideFields(override string) []formField {
+
+	if override == "" {
+		return []formField{}
+	}
+
+	return []formField{{"_method", override}}
+}
+
+// effectiveMethod returns the method a form is sent in place of, for forms
+// sent as a POST with the method overridden, or else method.
+func effectiveMethod(method string, data Form) string {
+
+	if o, ok := data.(interface{ override() string }); ok && o.override() != "" {
+		return o.override()
+	}
+
+	return method
+}
+
+type formField struct {
+	name  string
+	value string
+}
+
+// multipartFile is the file of a multipart body, read from either a path or
+// an Upload.
+type multipartFile struct {
+	field  string
+	path   string
+	upload *Upload
+}
+
+// multipartBody is a multipart body streamed from its file rather than
+// built in memory.
+type multipartBody struct {
+	io.Reader
+	file   io.Closer
+	length int64
+}
+
+func (m *multipartBody) Close() error {
+
+	if m.file == nil {
+		return nil
+	}
+
+	return m.file.Close()
+}
+
+// contentLength returns the length of the body, or -1 when it is not known.
+func (m *multipartBody) contentLength() int64 {
+	return m.length
+}
+
+// multipartForm encodes fields followed by file as a multipart body. Only
+// the part headers are held in memory; the contents of the file are read as
+// the body is sent. Unlike a body written through an io.Pipe, its length is
+// known whenever the size of the file is.
+func multipartForm(fields []formField, file multipartFile) (string, io.Reader, error) {
+
+	var (
+		content io.Reader
+		closer  io.Closer
+		name    string
+		size    int64
+	)
+
+	if file.upload != nil {
+
+		r, n, err := file.upload.open()
+		if err != nil {
+			return "", nil, err
+		}
+
+		content, name, size = r, file.upload.Name, n
+
+	} else {
+
+		f, err := os.Open(file.path)
+		if err != nil {
+			return "", nil, err
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return "", nil, err
+		}
+
+		content, closer, name, size = f, f, filepath.Base(file.path), info.Size()
+	}
+
+	head := bytes.NewBuffer(nil)
+	writer := multipart.NewWriter(head)
+
+	for _, f := range fields {
+		if err := writer.WriteField(f.name, f.value); err != nil {
+			return "", nil, closeOnError(closer, err)
+		}
+	}
+
+	if _, err := writer.CreateFormFile(file.field, name); err != nil {
+		return "", nil, closeOnError(closer, err)
+	}
+
+	// The closing boundary is sent after the file, so it is captured
+	// separately from the headers.
+	prefix := append([]byte(nil), head.Bytes()...)
+	head.Reset()
+
+	if err := writer.Close(); err != nil {
+		return "", nil, closeOnError(closer, err)
+	}
+
+	suffix := head.Bytes()
+
+	body := &multipartBody{
+		Reader: io.MultiReader(bytes.NewReader(prefix), content, bytes.NewReader(suffix)),
+		file:   closer,
+		length: -1,
+	}
+
+	if size >= 0 {
+		body.length = int64(len(prefix)) + size + int64(len(suffix))
+	}
+
+	return writer.FormDataContentType(), body, nil
+}
+
+func closeOnError(c io.Closer, err error) error {
+
+	if c != nil {
+		c.Close()
+	}
+
+	return err
+}
@@ -0,0 +1,166 @@
+package bookstack_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hcarriz/go-bookstack"
+	"github.com/hcarriz/go-bookstack/bookstacktest"
+	"github.com/stretchr/testify/require"
+)
+
+// onlyReader hides every method of a reader but Read, so its size and
+// position are unknown.
+type onlyReader struct {
+	r io.Reader
+}
+
+func (o onlyReader) Read(p []byte) (int, error) {
+	return o.r.Read(p)
+}
+
+func TestUpload(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	bk := srv.Client()
+
+	book, err := bk.CreateBook(ctx, bookstack.BookParams{
+		Name:        "Covered",
+		ImageUpload: &bookstack.Upload{Name: "cover.png", Reader: onlyReader{strings.NewReader("cover")}},
+	})
+	check.NoError(err)
+
+	detailed, err := bk.GetBook(ctx, book.ID)
+	check.NoError(err)
+	check.Equal("cover.png", detailed.Cover.Name)
+
+	page, err := bk.CreatePage(ctx, bookstack.PageParams{BookID: book.ID, Name: "Page", HTML: "<p>Page</p>"})
+	check.NoError(err)
+
+	attachment, err := bk.CreateAttachment(ctx, bookstack.AttachmentParams{
+		Name:       "Notes",
+		UploadedTo: page.ID,
+		FileUpload: &bookstack.Upload{Name: "notes.txt", Reader: bytes.NewReader([]byte("some notes"))},
+	})
+	check.NoError(err)
+
+	got, err := bk.GetAttachment(ctx, attachment.ID)
+	check.NoError(err)
+	check.Equal("txt", got.Extension)
+	check.Equal(base64.StdEncoding.EncodeToString([]byte("some notes")), got.Content)
+}
+
+func TestUploadContentLength(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	var (
+		length int64
+		file   string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		length = r.ContentLength
+
+		f, _, err := r.FormFile("file")
+		check.NoError(err)
+
+		data, err := io.ReadAll(f)
+		check.NoError(err)
+
+		file = string(data)
+
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer srv.Close()
+
+	bk := bookstack.New(bookstack.SetURL(srv.URL))
+
+	_, err := bk.CreateAttachment(ctx, bookstack.AttachmentParams{
+		Name:       "Sized",
+		UploadedTo: 1,
+		FileUpload: &bookstack.Upload{Name: "sized.txt", Reader: onlyReader{strings.NewReader("sized")}, Size: 5},
+	})
+	check.NoError(err)
+	check.Positive(length)
+	check.Equal("sized", file)
+
+	_, err = bk.CreateAttachment(ctx, bookstack.AttachmentParams{
+		Name:       "Unsized",
+		UploadedTo: 1,
+		FileUpload: &bookstack.Upload{Name: "unsized.txt", Reader: onlyReader{strings.NewReader("unsized")}},
+	})
+	check.NoError(err)
+	check.Equal(int64(-1), length)
+	check.Equal("unsized", file)
+}
+
+func TestUploadRetry(t *testing.T) {
+
+	check := require.New(t)
+
+	ctx := context.Background()
+
+	policy := bookstack.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	t.Run("seekable readers are rewound", func(t *testing.T) {
+
+		var files []string
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			f, _, err := r.FormFile("file")
+			check.NoError(err)
+
+			data, err := io.ReadAll(f)
+			check.NoError(err)
+
+			files = append(files, string(data))
+
+			if len(files) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.Write([]byte(`{"id":1}`))
+		}))
+		defer srv.Close()
+
+		reader := strings.NewReader("header:contents")
+		reader.Seek(int64(len("header:")), io.SeekStart)
+
+		_, err := bookstack.New(bookstack.SetURL(srv.URL), bookstack.SetRetryPolicy(policy)).UpdateAttachment(ctx, 1, bookstack.AttachmentParams{
+			FileUpload: &bookstack.Upload{Name: "contents.txt", Reader: reader},
+		})
+		check.NoError(err)
+		check.Equal([]string{"contents", "contents"}, files)
+	})
+
+	t.Run("other readers are sent once", func(t *testing.T) {
+
+		srv, calls := flaky(1, http.StatusServiceUnavailable, nil, `{"id":1}`)
+		defer srv.Close()
+
+		_, err := bookstack.New(bookstack.SetURL(srv.URL), bookstack.SetRetryPolicy(policy)).UpdateAttachment(ctx, 1, bookstack.AttachmentParams{
+			FileUpload: &bookstack.Upload{Name: "stream.txt", Reader: onlyReader{strings.NewReader("stream")}},
+		})
+		check.Error(err)
+		check.Equal(int32(1), *calls)
+	})
+}
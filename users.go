@@ -71,6 +71,18 @@ func (b *Bookstack) ListUsers(ctx context.Context, params *QueryParams) ([]User,
 	return ParseMultiple[[]User](resp)
 }
 
+// ListUsersResult will return a page of the users that match the given params,
+// along with the total and the pagination applied.
+func (b *Bookstack) ListUsersResult(ctx context.Context, params *QueryParams) (ListResult[User], error) {
+	return List[User](ctx, b, "/users", params)
+}
+
+// AllUsers will return every user that matches the given params, following
+// pagination. The Count of params sets the page size.
+func (b *Bookstack) AllUsers(ctx context.Context, params *QueryParams) ([]User, error) {
+	return Paginate[User](ctx, b, "/users", params).All()
+}
+
 // GetUser will return the user assigned to the given id, or an error.
 func (b *Bookstack) GetUser(ctx context.Context, id int) (User, error) {
 
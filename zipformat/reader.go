@@ -0,0 +1,135 @@
+package zipformat
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrNoManifest is returned when a ZIP has no data.json.
+var ErrNoManifest = errors.New("zipformat: missing " + ManifestName)
+
+// Reader reads a ZIP. The manifest is parsed and validated when the Reader
+// is created.
+type Reader struct {
+	Manifest Manifest
+
+	zip   *zip.Reader
+	files map[string]*zip.File
+	close func() error
+}
+
+// OpenReader opens the ZIP at name.
+func OpenReader(name string) (*Reader, error) {
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	r, err := NewReader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	r.close = f.Close
+
+	return r, nil
+}
+
+// NewReader reads a ZIP of the given size from r.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Reader{zip: z, files: map[string]*zip.File{}}
+
+	var manifest *zip.File
+
+	for _, f := range z.File {
+
+		switch {
+		case f.Name == ManifestName:
+			manifest = f
+		case strings.HasPrefix(f.Name, FilesDir) && !strings.HasSuffix(f.Name, "/"):
+			result.files[strings.TrimPrefix(f.Name, FilesDir)] = f
+		}
+	}
+
+	if manifest == nil {
+		return nil, ErrNoManifest
+	}
+
+	rc, err := manifest.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	defer rc.Close()
+
+	if err := json.NewDecoder(rc).Decode(&result.Manifest); err != nil {
+		return nil, fmt.Errorf("zipformat: decoding %s: %w", ManifestName, err)
+	}
+
+	if err := Validate(&result.Manifest, result.Has); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Has reports whether the files directory holds name.
+func (r *Reader) Has(name string) bool {
+	_, ok := r.files[name]
+	return ok
+}
+
+// Files returns the names of the files in the files directory, sorted.
+func (r *Reader) Files() []string {
+
+	names := make([]string, 0, len(r.files))
+	for name := range r.files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Open opens the file called name in the files directory.
+func (r *Reader) Open(name string) (io.ReadCloser, error) {
+
+	f, ok := r.files[name]
+	if !ok {
+		return nil, fmt.Errorf("zipformat: %s%s: %w", FilesDir, name, os.ErrNotExist)
+	}
+
+	return f.Open()
+}
+
+// Close closes the underlying file when the Reader was created with
+// OpenReader.
+func (r *Reader) Close() error {
+
+	if r.close == nil {
+		return nil
+	}
+
+	return r.close()
+}
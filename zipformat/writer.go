@@ -0,0 +1,85 @@
+package zipformat
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Writer writes a ZIP. Files are added first, and the manifest is written,
+// after being validated, by Close.
+type Writer struct {
+	zip   *zip.Writer
+	files map[string]bool
+}
+
+// NewWriter returns a Writer writing a ZIP to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zip: zip.NewWriter(w), files: map[string]bool{}}
+}
+
+// Create adds a file called name to the files directory and returns a
+// writer for its contents, which is valid until the next call to Create,
+// AddFile or Close.
+func (w *Writer) Create(name string) (io.Writer, error) {
+
+	if !validName(name) {
+		return nil, fmt.Errorf("zipformat: %q is not a valid file name", name)
+	}
+
+	if w.files[name] {
+		return nil, fmt.Errorf("zipformat: duplicate file %q", name)
+	}
+
+	f, err := w.zip.Create(FilesDir + name)
+	if err != nil {
+		return nil, err
+	}
+
+	w.files[name] = true
+
+	return f, nil
+}
+
+// AddFile adds a file called name with the contents of r to the files
+// directory.
+func (w *Writer) AddFile(name string, r io.Reader) error {
+
+	f, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+
+	return err
+}
+
+// Close validates m against the added files, writes it as the manifest and
+// finishes the ZIP. The ZIP is left incomplete when m is invalid.
+func (w *Writer) Close(m *Manifest) error {
+
+	if m == nil {
+		return errors.New("zipformat: nil manifest")
+	}
+
+	if err := Validate(m, func(name string) bool { return w.files[name] }); err != nil {
+		return err
+	}
+
+	f, err := w.zip.Create(ManifestName)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+
+	return w.zip.Close()
+}
@@ -0,0 +1,314 @@
+// Package zipformat reads and writes the portable ZIP files that BookStack
+// produces for ZIP exports and accepts for imports.
+//
+// A ZIP holds a data.json manifest describing a single book, chapter or
+// page, along with the files it references, such as covers, images and
+// attachments, stored under the files/ directory.
+package zipformat
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ManifestName is the name of the manifest within a ZIP.
+	ManifestName = "data.json"
+	// FilesDir is the directory that holds the files referenced by the
+	// manifest.
+	FilesDir = "files/"
+)
+
+// Manifest is the data.json of a ZIP. Exactly one of Book, Chapter and Page
+// is set.
+type Manifest struct {
+	Instance   Instance  `json:"instance"`
+	ExportedAt time.Time `json:"exported_at"`
+	Book       *Book     `json:"book,omitempty"`
+	Chapter    *Chapter  `json:"chapter,omitempty"`
+	Page       *Page     `json:"page,omitempty"`
+}
+
+// Instance identifies the server that produced a ZIP.
+type Instance struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// Book is an exported book. IDs are those of the exporting instance and are
+// only used to resolve references between items of the same ZIP.
+type Book struct {
+	ID              int    `json:"id,omitempty"`
+	Name            string `json:"name"`
+	DescriptionHTML string `json:"description_html,omitempty"`
+	// Cover is the name of the cover image within the files directory.
+	Cover    string    `json:"cover,omitempty"`
+	Chapters []Chapter `json:"chapters,omitempty"`
+	Pages    []Page    `json:"pages,omitempty"`
+	Tags     []Tag     `json:"tags,omitempty"`
+}
+
+type Chapter struct {
+	ID              int    `json:"id,omitempty"`
+	Name            string `json:"name"`
+	DescriptionHTML string `json:"description_html,omitempty"`
+	Priority        int    `json:"priority,omitempty"`
+	Pages           []Page `json:"pages,omitempty"`
+	Tags            []Tag  `json:"tags,omitempty"`
+}
+
+type Page struct {
+	ID          int          `json:"id,omitempty"`
+	Name        string       `json:"name"`
+	HTML        string       `json:"html,omitempty"`
+	Markdown    string       `json:"markdown,omitempty"`
+	Priority    int          `json:"priority,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Images      []Image      `json:"images,omitempty"`
+	Tags        []Tag        `json:"tags,omitempty"`
+}
+
+// Image is an image used by a page. File is the name of the image within the
+// files directory.
+type Image struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name"`
+	File string `json:"file"`
+	Type string `json:"type"`
+}
+
+// Attachment is a file or link attached to a page. Exactly one of Link and
+// File is set, File being the name of the file within the files directory.
+type Attachment struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name"`
+	Link string `json:"link,omitempty"`
+	File string `json:"file,omitempty"`
+}
+
+type Tag struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// Type returns the kind of item the manifest holds: "book", "chapter" or
+// "page". It is empty when none is set.
+func (m *Manifest) Type() string {
+
+	switch {
+	case m.Book != nil:
+		return "book"
+	case m.Chapter != nil:
+		return "chapter"
+	case m.Page != nil:
+		return "page"
+	}
+
+	return ""
+}
+
+// Name returns the name of the item the manifest holds.
+func (m *Manifest) Name() string {
+
+	switch {
+	case m.Book != nil:
+		return m.Book.Name
+	case m.Chapter != nil:
+		return m.Chapter.Name
+	case m.Page != nil:
+		return m.Page.Name
+	}
+
+	return ""
+}
+
+// ValidationError lists the problems found in a manifest.
+type ValidationError struct {
+	// Problems are keyed by the path of the invalid field, such as
+	// "book.chapters[0].name".
+	Problems map[string]string
+}
+
+func (e *ValidationError) Error() string {
+
+	keys := make([]string, 0, len(e.Problems))
+	for k := range e.Problems {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s: %s", k, e.Problems[k]))
+	}
+
+	return "zipformat: invalid manifest: " + strings.Join(parts, "; ")
+}
+
+// Validate checks that m is complete and that every file it references is
+// present. exists reports whether a name is present in the files directory;
+// when nil, file references are not checked. The returned error is a
+// *ValidationError.
+func Validate(m *Manifest, exists func(name string) bool) error {
+
+	v := validator{problems: map[string]string{}, exists: exists}
+
+	if m.Instance.ID == "" {
+		v.fail("instance.id", "is required")
+	}
+
+	if m.Instance.Version == "" {
+		v.fail("instance.version", "is required")
+	}
+
+	if m.ExportedAt.IsZero() {
+		v.fail("exported_at", "is required")
+	}
+
+	set := 0
+	for _, ok := range []bool{m.Book != nil, m.Chapter != nil, m.Page != nil} {
+		if ok {
+			set++
+		}
+	}
+
+	if set != 1 {
+		v.fail("book", "exactly one of book, chapter or page is required")
+	}
+
+	switch {
+	case m.Book != nil:
+		v.book("book", m.Book)
+	case m.Chapter != nil:
+		v.chapter("chapter", m.Chapter)
+	case m.Page != nil:
+		v.page("page", m.Page)
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+
+	return nil
+}
+
+type validator struct {
+	problems map[string]string
+	exists   func(string) bool
+}
+
+func (v *validator) fail(field, problem string) {
+	if _, ok := v.problems[field]; !ok {
+		v.problems[field] = problem
+	}
+}
+
+func (v *validator) file(field, name string) {
+
+	if !validName(name) {
+		v.fail(field, fmt.Sprintf("%q is not a valid file name", name))
+		return
+	}
+
+	if v.exists != nil && !v.exists(name) {
+		v.fail(field, fmt.Sprintf("file %q is missing", name))
+	}
+}
+
+func (v *validator) book(field string, b *Book) {
+
+	if b.Name == "" {
+		v.fail(field+".name", "is required")
+	}
+
+	if b.Cover != "" {
+		v.file(field+".cover", b.Cover)
+	}
+
+	v.tags(field, b.Tags)
+
+	for i := range b.Chapters {
+		v.chapter(fmt.Sprintf("%s.chapters[%d]", field, i), &b.Chapters[i])
+	}
+
+	for i := range b.Pages {
+		v.page(fmt.Sprintf("%s.pages[%d]", field, i), &b.Pages[i])
+	}
+}
+
+func (v *validator) chapter(field string, c *Chapter) {
+
+	if c.Name == "" {
+		v.fail(field+".name", "is required")
+	}
+
+	v.tags(field, c.Tags)
+
+	for i := range c.Pages {
+		v.page(fmt.Sprintf("%s.pages[%d]", field, i), &c.Pages[i])
+	}
+}
+
+func (v *validator) page(field string, p *Page) {
+
+	if p.Name == "" {
+		v.fail(field+".name", "is required")
+	}
+
+	v.tags(field, p.Tags)
+
+	for i, img := range p.Images {
+
+		f := fmt.Sprintf("%s.images[%d]", field, i)
+
+		if img.Name == "" {
+			v.fail(f+".name", "is required")
+		}
+
+		if img.Type != "gallery" && img.Type != "drawio" {
+			v.fail(f+".type", "must be gallery or drawio")
+		}
+
+		if img.File == "" {
+			v.fail(f+".file", "is required")
+		} else {
+			v.file(f+".file", img.File)
+		}
+	}
+
+	for i, a := range p.Attachments {
+
+		f := fmt.Sprintf("%s.attachments[%d]", field, i)
+
+		if a.Name == "" {
+			v.fail(f+".name", "is required")
+		}
+
+		switch {
+		case a.File == "" && a.Link == "":
+			v.fail(f, "one of link or file is required")
+		case a.File != "" && a.Link != "":
+			v.fail(f, "only one of link or file is allowed")
+		case a.File != "":
+			v.file(f+".file", a.File)
+		}
+	}
+}
+
+func (v *validator) tags(field string, tags []Tag) {
+	for i, t := range tags {
+		if t.Name == "" {
+			v.fail(fmt.Sprintf("%s.tags[%d].name", field, i), "is required")
+		}
+	}
+}
+
+// validName reports whether name can be stored directly in the files
+// directory.
+func validName(name string) bool {
+	return name != "" && name == path.Base(name) && name != "." && name != ".."
+}
@@ -0,0 +1,122 @@
+package zipformat_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hcarriz/go-bookstack/zipformat"
+	"github.com/stretchr/testify/require"
+)
+
+func manifest() *zipformat.Manifest {
+	return &zipformat.Manifest{
+		Instance:   zipformat.Instance{ID: "b5f0e7a8", Version: "v25.02"},
+		ExportedAt: time.Date(2025, time.February, 1, 9, 30, 0, 0, time.UTC),
+		Book: &zipformat.Book{
+			Name:  "Handbook",
+			Cover: "cover.png",
+			Tags:  []zipformat.Tag{{Name: "team", Value: "ops"}},
+			Chapters: []zipformat.Chapter{{
+				Name: "Onboarding",
+				Pages: []zipformat.Page{{
+					Name:        "Welcome",
+					HTML:        "<p>Hello</p>",
+					Images:      []zipformat.Image{{Name: "Logo", File: "logo.png", Type: "gallery"}},
+					Attachments: []zipformat.Attachment{{Name: "Site", Link: "https://example.com"}},
+				}},
+			}},
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	check := require.New(t)
+
+	var buf bytes.Buffer
+
+	w := zipformat.NewWriter(&buf)
+	check.NoError(w.AddFile("cover.png", strings.NewReader("cover")))
+	check.NoError(w.AddFile("logo.png", strings.NewReader("logo")))
+	check.Error(w.AddFile("logo.png", strings.NewReader("again")))
+	check.Error(w.AddFile("../escape.png", strings.NewReader("nope")))
+	check.NoError(w.Close(manifest()))
+
+	r, err := zipformat.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	check.NoError(err)
+	check.Equal(manifest(), &r.Manifest)
+	check.Equal("book", r.Manifest.Type())
+	check.Equal("Handbook", r.Manifest.Name())
+	check.Equal([]string{"cover.png", "logo.png"}, r.Files())
+
+	f, err := r.Open("logo.png")
+	check.NoError(err)
+
+	data, err := ioutil.ReadAll(f)
+	check.NoError(err)
+	check.NoError(f.Close())
+	check.Equal("logo", string(data))
+
+	_, err = r.Open("missing.png")
+	check.ErrorIs(err, os.ErrNotExist)
+
+	path := filepath.Join(t.TempDir(), "export.zip")
+	check.NoError(ioutil.WriteFile(path, buf.Bytes(), 0o600))
+
+	opened, err := zipformat.OpenReader(path)
+	check.NoError(err)
+	check.Equal("Handbook", opened.Manifest.Name())
+	check.NoError(opened.Close())
+}
+
+func TestValidate(t *testing.T) {
+
+	check := require.New(t)
+
+	check.NoError(zipformat.Validate(manifest(), nil))
+
+	m := manifest()
+	m.Instance = zipformat.Instance{}
+	m.Page = &zipformat.Page{Name: "Extra"}
+	m.Book.Chapters[0].Pages[0].Name = ""
+	m.Book.Chapters[0].Pages[0].Images[0].Type = "cover"
+	m.Book.Chapters[0].Pages[0].Attachments[0].File = "site.html"
+
+	err := zipformat.Validate(m, func(name string) bool { return name == "cover.png" })
+
+	var invalid *zipformat.ValidationError
+	check.True(errors.As(err, &invalid))
+	check.Contains(invalid.Problems, "instance.id")
+	check.Contains(invalid.Problems, "book")
+	check.Contains(invalid.Problems, "book.chapters[0].pages[0].name")
+	check.Contains(invalid.Problems, "book.chapters[0].pages[0].images[0].type")
+	check.Contains(invalid.Problems, "book.chapters[0].pages[0].images[0].file")
+	check.Contains(invalid.Problems, "book.chapters[0].pages[0].attachments[0]")
+	check.NotContains(invalid.Problems, "book.cover")
+
+	// A manifest that is invalid is not written.
+	var buf bytes.Buffer
+	check.Error(zipformat.NewWriter(&buf).Close(m))
+}
+
+func TestReaderMissingManifest(t *testing.T) {
+
+	check := require.New(t)
+
+	var buf bytes.Buffer
+
+	z := zip.NewWriter(&buf)
+	_, err := z.Create("files/logo.png")
+	check.NoError(err)
+	check.NoError(z.Close())
+
+	_, err = zipformat.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	check.ErrorIs(err, zipformat.ErrNoManifest)
+}